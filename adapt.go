@@ -7,23 +7,59 @@ import (
 
 // Adapt wraps the stdlib database to provide an implementation of the same interface this library
 // provides for using the AWS Aurora Data API.
-func Adapt(db *sql.DB) *StdDB { return &StdDB{db} }
+func Adapt(db *sql.DB, opts ...AdaptOption) *StdDB {
+	sdb := &StdDB{db: db, mapper: defaultMapper}
+	for _, o := range opts {
+		o(sdb)
+	}
+
+	return sdb
+}
+
+// AdaptOption configures the adapted database
+type AdaptOption func(db *StdDB)
+
+// AdaptDialect configures the dialect of the adapted database. Named placeholders (':name') are
+// then rewritten into the positional placeholders of that dialect and the sql.NamedArg arguments
+// are reordered to match. This allows queries written for the Data API to run on drivers that
+// don't support named parameters.
+func AdaptDialect(d Dialect) AdaptOption { return func(db *StdDB) { db.dialect = d } }
+
+// AdaptNameMapper configures how struct fields without a 'db' tag are mapped to column names by
+// ScanStruct and ScanAll. By default the field name is lower cased.
+func AdaptNameMapper(fn NameMapper) AdaptOption {
+	return func(db *StdDB) { db.mapper = newStructMapper(fn) }
+}
 
 // StdDB wraps a *sql.DB
-type StdDB struct{ db *sql.DB }
+type StdDB struct {
+	db      *sql.DB
+	dialect Dialect
+	mapper  *structMapper
+}
 
 // ExecBatch sets up a prepared statement and runs the whole batch in it
 func (db *StdDB) ExecBatch(ctx context.Context, b *Batch) ([]Result, error) {
-	return batch(ctx, b, db.db.PrepareContext)
+	return batch(ctx, b, db.dialect, db.mapper, db.db.PrepareContext)
 }
 
 // Query executes sql for a query that is expected to return rows
 func (db *StdDB) Query(ctx context.Context, q string, args ...interface{}) (Rows, error) {
-	return db.db.QueryContext(ctx, q, args...)
+	q, args, err := stdArgs(q, db.dialect, db.mapper, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return stdQuery(db.mapper)(db.db.QueryContext(ctx, q, args...))
 }
 
 // Exec executes sql for a query that doesn't return any results
 func (db *StdDB) Exec(ctx context.Context, q string, args ...interface{}) (Result, error) {
+	q, args, err := stdArgs(q, db.dialect, db.mapper, args)
+	if err != nil {
+		return nil, err
+	}
+
 	return db.db.ExecContext(ctx, q, args...)
 }
 
@@ -34,38 +70,149 @@ func (db *StdDB) Tx(ctx context.Context) (Tx, error) {
 		return nil, err
 	}
 
-	return &stdTx{tx}, nil
+	return &stdTx{tx, db.dialect, db.mapper}, nil
 }
 
 // stdTx wraps *sql.Tx while implementing this package's Tx interface
-type stdTx struct{ tx *sql.Tx }
+type stdTx struct {
+	tx      *sql.Tx
+	dialect Dialect
+	mapper  *structMapper
+}
 
 func (tx *stdTx) Commit() error   { return tx.tx.Commit() }
 func (tx *stdTx) Rollback() error { return tx.tx.Rollback() }
 
 func (tx *stdTx) Exec(ctx context.Context, q string, args ...interface{}) (Result, error) {
+	q, args, err := stdArgs(q, tx.dialect, tx.mapper, args)
+	if err != nil {
+		return nil, err
+	}
+
 	return tx.tx.ExecContext(ctx, q, args...)
 }
 
 func (tx *stdTx) Query(ctx context.Context, q string, args ...interface{}) (Rows, error) {
-	return tx.tx.QueryContext(ctx, q, args...)
+	q, args, err := stdArgs(q, tx.dialect, tx.mapper, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return stdQuery(tx.mapper)(tx.tx.QueryContext(ctx, q, args...))
 }
 
 func (tx *stdTx) ExecBatch(ctx context.Context, b *Batch) ([]Result, error) {
-	return batch(ctx, b, tx.tx.PrepareContext)
+	return batch(ctx, b, tx.dialect, tx.mapper, tx.tx.PrepareContext)
+}
+
+// stdRows wraps *sql.Rows while implementing this package's Rows interface
+type stdRows struct {
+	*sql.Rows
+	mapper *structMapper
+}
+
+// stdQuery returns a function that wraps the result of a query on the standard library database
+func stdQuery(m *structMapper) func(rows *sql.Rows, err error) (Rows, error) {
+	return func(rows *sql.Rows, err error) (Rows, error) {
+		if err != nil {
+			return nil, err
+		}
+
+		return &stdRows{rows, m}, nil
+	}
+}
+
+// ScanStruct scans the current row into the fields of a struct
+func (r *stdRows) ScanStruct(dst interface{}) error {
+	cols, err := r.Rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	dest, err := r.mapper.dest(cols, dst)
+	if err != nil {
+		return err
+	}
+
+	return r.Rows.Scan(dest...)
+}
+
+// ColumnTypes returns the column types of the standard library rows
+func (r *stdRows) ColumnTypes() ([]ColumnType, error) {
+	scts, err := r.Rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	cts := make([]ColumnType, len(scts))
+	for i, sct := range scts {
+		cts[i] = stdColumnType{sct}
+	}
+
+	return cts, nil
+}
+
+// stdColumnType wraps *sql.ColumnType, which doesn't know about signedness and table names
+type stdColumnType struct{ *sql.ColumnType }
+
+func (ct stdColumnType) Signed() (signed, ok bool)         { return false, false }
+func (ct stdColumnType) TableName() (name string, ok bool) { return "", false }
+
+// stdArgs expands the NamedFrom arguments and rewrites the named placeholders for dialect 'd'
+func stdArgs(q string, d Dialect, m *structMapper, args []interface{}) (string, []interface{}, error) {
+	args, err := expandNamed(q, d, m, args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if q, args, err = expandLists(q, d, 0, nil, args); err != nil {
+		return "", nil, err
+	}
+
+	return rewriteNamedArgs(q, d, args)
 }
 
 func batch(
 	ctx context.Context,
 	b *Batch,
+	d Dialect,
+	m *structMapper,
 	pf func(ctx context.Context, query string) (*sql.Stmt, error),
 ) ([]Result, error) {
-	stmt, err := pf(ctx, b.sql)
+	q, names := b.sql, []string(nil)
+	if d != DialectNone {
+		q, names = rewriteNamed(b.sql, d)
+	}
+
+	qrys, exes := make([][]interface{}, len(b.qrys)), make([][]interface{}, len(b.exes))
+	for i, args := range b.qrys {
+		var err error
+		if args, err = expandNamed(b.sql, d, m, args); err != nil {
+			return nil, err
+		}
+
+		if qrys[i], err = bindNamed(names, args); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, args := range b.exes {
+		var err error
+		if args, err = expandNamed(b.sql, d, m, args); err != nil {
+			return nil, err
+		}
+
+		if exes[i], err = bindNamed(names, args); err != nil {
+			return nil, err
+		}
+	}
+
+	stmt, err := pf(ctx, q)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, args := range b.qrys {
+	for _, args := range qrys {
 		rows, err := stmt.Query(args...)
 		if err != nil {
 			// @TODO collect all errors?
@@ -73,7 +220,7 @@ func batch(
 		_ = rows // @TODO turn rows into our result struct
 	}
 
-	for _, args := range b.exes {
+	for _, args := range exes {
 		res, err := stmt.Exec(args...)
 		if err != nil {
 			// @TODO collect errors?
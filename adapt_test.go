@@ -1,6 +1,17 @@
 package dasql
 
-import "context"
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
 
 // TXDB is the interface shared between a transaction and the DB
 type TXDB interface {
@@ -12,3 +23,183 @@ type TXDB interface {
 var _ TXDB = &StdDB{}
 var _ TXDB = &DB{}
 var _ TXDB = Tx(nil)
+
+func TestAdaptDialect(t *testing.T) {
+	da, ctx := &stubDA{
+		nextESO:  &rdsdataservice.ExecuteStatementOutput{},
+		nextBTO:  &rdsdataservice.BeginTransactionOutput{TransactionId: aws.String("1234")},
+		nextBESO: &rdsdataservice.BatchExecuteStatementOutput{},
+	}, context.Background()
+
+	// the adapted database rewrites into positional placeholders, which the Data API driver then
+	// rewrites back into named placeholders for the stub.
+	sdb := sql.OpenDB(NewConnector(New(da, "", "", WithDialect(DialectPostgres))))
+	db := Adapt(sdb, AdaptDialect(DialectPostgres))
+
+	if _, err := db.Exec(ctx, `UPDATE foo SET a = :a, b = :b WHERE a = :a`,
+		sql.Named("b", 2), sql.Named("a", 1)); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.Sql); act != `UPDATE foo SET a = :p1, b = :p2 WHERE a = :p1` {
+		t.Fatalf("got: %v", act)
+	}
+
+	if act := aws.Int64Value(da.lastESI.Parameters[1].Value.LongValue); act != 2 {
+		t.Fatalf("got: %v", act)
+	}
+
+	tx, err := db.Tx(ctx)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err = tx.Query(ctx, `SELECT * FROM foo WHERE a = :a`); !errors.Is(err,
+		ArgErr{Kind: ArgErrKindMissing, Names: []string{"a"}}) {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err = tx.Query(ctx, `SELECT * FROM foo WHERE a = :a`, sql.Named("a", 1)); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.TransactionId); act != "1234" {
+		t.Fatalf("got: %v", act)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestAdaptList(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{}}, context.Background()
+	sdb := sql.OpenDB(NewConnector(New(da, "", "", WithDialect(DialectMySQL))))
+	db := Adapt(sdb, AdaptDialect(DialectMySQL))
+
+	if _, err := db.Exec(ctx, `DELETE FROM foo WHERE id IN (:ids)`,
+		sql.Named("ids", []int64{1, 2})); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.Sql); act != `DELETE FROM foo WHERE id IN (:p1, :p2)` {
+		t.Fatalf("got: %v", act)
+	}
+}
+
+func TestAdaptBatchDialect(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{}}, context.Background()
+	sdb := sql.OpenDB(NewConnector(New(da, "", "", WithDialect(DialectMySQL))))
+	db := Adapt(sdb, AdaptDialect(DialectMySQL))
+
+	b := NewBatch(`INSERT INTO foo (a) VALUES (:a)`).Exec(sql.Named("a", 1))
+	if _, err := db.ExecBatch(ctx, b); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.Sql); act != `INSERT INTO foo (a) VALUES (:p1)` {
+		t.Fatalf("got: %v", act)
+	}
+
+	b = NewBatch(`INSERT INTO foo (a) VALUES (:a)`).Exec(1)
+	if _, err := db.ExecBatch(ctx, b); !errors.Is(err, ArgErr{Kind: ArgErrKindPositional}) {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestAdaptColumnTypes(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{
+		ColumnMetadata: []*rdsdataservice.ColumnMetadata{
+			{Name: aws.String("id"), TypeName: aws.String("BIGINT"), Nullable: aws.Int64(0)},
+			{Name: aws.String("price"), TypeName: aws.String("NUMERIC"), Nullable: aws.Int64(1),
+				Precision: aws.Int64(10), Scale: aws.Int64(2)},
+		},
+		Records: [][]*rdsdataservice.Field{
+			{{LongValue: aws.Int64(1)}, {StringValue: aws.String("1.50")}},
+		},
+	}}, context.Background()
+
+	db := Adapt(sql.OpenDB(NewConnector(New(da, "", ""))))
+	rows, err := db.Query(ctx, `SELECT id, price FROM foo`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	defer rows.Close()
+
+	if !aws.BoolValue(da.lastESI.IncludeResultMetadata) {
+		t.Fatalf("got: %v", da.lastESI.IncludeResultMetadata)
+	}
+
+	cols, err := Columns(rows)
+	if err != nil || len(cols) != 2 || cols[0] != "id" || cols[1] != "price" {
+		t.Fatalf("got: %v %v", cols, err)
+	}
+
+	cts, err := ColumnTypes(rows)
+	if err != nil || len(cts) != 2 {
+		t.Fatalf("got: %v %v", cts, err)
+	}
+
+	if act := cts[0].DatabaseTypeName(); act != "BIGINT" {
+		t.Fatalf("got: %v", act)
+	}
+
+	if p, s, ok := cts[1].DecimalSize(); !ok || p != 10 || s != 2 {
+		t.Fatalf("got: %v %v %v", p, s, ok)
+	}
+
+	if n, ok := cts[1].Nullable(); !ok || !n {
+		t.Fatalf("got: %v %v", n, ok)
+	}
+
+	if _, ok := cts[0].TableName(); ok {
+		t.Fatalf("got: %v", ok)
+	}
+}
+
+// argsConnector connects to a driver that records the arguments of Exec, it has no named value
+// checker so database/sql converts the arguments with its default converter
+type argsConnector struct{ args []driver.NamedValue }
+
+func (c *argsConnector) Connect(context.Context) (driver.Conn, error) { return argsConn{c}, nil }
+func (c *argsConnector) Driver() driver.Driver                        { return nil }
+
+type argsConn struct{ c *argsConnector }
+
+func (argsConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (argsConn) Close() error                        { return nil }
+func (argsConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c argsConn) ExecContext(
+	ctx context.Context, q string, args []driver.NamedValue,
+) (driver.Result, error) {
+	c.c.args = args
+	return driver.RowsAffected(1), nil
+}
+
+func TestAdaptTypedArgs(t *testing.T) {
+	conn, ctx := &argsConnector{}, context.Background()
+	db := Adapt(sql.OpenDB(conn))
+
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if _, err := db.Exec(ctx, `INSERT INTO foo (d, t, j, n) VALUES (:d, :t, :j, :n)`,
+		sql.Named("d", Date(at)), sql.Named("t", Time(at)), sql.Named("j", JSON(map[string]int{"a": 1})),
+		sql.Named("n", Decimal("1.20"))); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var act []interface{}
+	for _, nv := range conn.args {
+		act = append(act, nv.Value)
+	}
+
+	if exp := []interface{}{"2020-01-02", "03:04:05", `{"a":1}`, "1.20"}; !reflect.DeepEqual(act, exp) {
+		t.Fatalf("exp: %v got: %v", exp, act)
+	}
+
+	if _, err := db.Exec(ctx, `INSERT INTO foo (j) VALUES (:j)`,
+		sql.Named("j", JSON(func() {}))); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+}
@@ -2,8 +2,15 @@ package dasql
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/rdsdataservice"
@@ -11,14 +18,54 @@ import (
 
 // ArgErr represents an erro the convert arguments int Data API parameters
 type ArgErr struct {
-	Kind ArgErrKind
-	Type string
+	Kind  ArgErrKind
+	Type  string
+	Names []string // names of the offending parameters, if any
+	err   error
+}
+
+// Unwrap returns the underlying error, if any
+func (e ArgErr) Unwrap() error {
+	return e.err
+}
+
+// Is implements error comparison
+func (e ArgErr) Is(other error) bool {
+	ee, ok := other.(ArgErr)
+	if !ok || ee.Kind != e.Kind || ee.Type != e.Type || len(ee.Names) != len(e.Names) {
+		return false
+	}
+
+	for i, name := range ee.Names {
+		if e.Names[i] != name {
+			return false
+		}
+	}
+
+	return true
 }
 
 func (e ArgErr) Error() string {
 	switch e.Kind {
 	case ArgErrKindUnsupported:
 		return fmt.Sprintf("unsupported argument type, got: %v", e.Type)
+	case ArgErrKindPositional:
+		if e.Type != "" {
+			return fmt.Sprintf("positional placeholder has no matching argument, got: %v", e.Type)
+		}
+		return "number of positional arguments doesn't match the placeholders"
+	case ArgErrKindMissing:
+		return fmt.Sprintf("missing arguments for placeholders: %s", strings.Join(e.Names, ", "))
+	case ArgErrKindUnused:
+		return fmt.Sprintf("arguments without a placeholder: %s", strings.Join(e.Names, ", "))
+	case ArgErrKindDuplicate:
+		return fmt.Sprintf("arguments with the same name: %s", strings.Join(e.Names, ", "))
+	case ArgErrKindInvalidName:
+		return fmt.Sprintf("invalid argument names: %q", e.Names)
+	case ArgErrKindListSize:
+		return fmt.Sprintf("unsupported number of elements in list arguments: %s", strings.Join(e.Names, ", "))
+	case ArgErrKindValuer:
+		return fmt.Sprintf("failed to get the value of argument type %v: %v", e.Type, e.err)
 	default:
 		return "error while converting argument"
 	}
@@ -33,19 +80,54 @@ const (
 
 	// ArgErrKindUnsupported is returned when an unsupported arg type is returned
 	ArgErrKindUnsupported
+
+	// ArgErrKindPositional is returned when positional arguments don't match the placeholders
+	ArgErrKindPositional
+
+	// ArgErrKindMissing is returned when there are named placeholders without an argument
+	ArgErrKindMissing
+
+	// ArgErrKindValuer is returned when the Value method of a driver.Valuer argument fails
+	ArgErrKindValuer
+
+	// ArgErrKindListSize is returned when a list argument has more elements than allowed, or none
+	// while it's not used as the only element of an IN list.
+	ArgErrKindListSize
+
+	// ArgErrKindUnused is returned when there are named arguments without a placeholder
+	ArgErrKindUnused
+
+	// ArgErrKindDuplicate is returned when there are named arguments with the same name
+	ArgErrKindDuplicate
+
+	// ArgErrKindInvalidName is returned when an argument name can't be used as a placeholder
+	ArgErrKindInvalidName
 )
 
 // ConvertArgs converts the provided named arguments into a slice of rds data parameters. It
-// currently only supports sql.NamedArg values but this might change in the future.
+// only supports sql.NamedArg values, positional arguments are only supported by a DB that is
+// configured with a dialect (see WithDialect). Values are converted like the default converter of
+// database/sql does, so types that implement driver.Valuer are supported.
 func ConvertArgs(args ...interface{}) (ps []*rdsdataservice.SqlParameter, err error) {
+	return defaultCodec.convertArgs(args...)
+}
+
+// ConvertArgs converts the provided named arguments into parameters like the package level
+// ConvertArgs does, with the conversion options (location, type registry) of the DB.
+func (db *DB) ConvertArgs(args ...interface{}) ([]*rdsdataservice.SqlParameter, error) {
+	return db.codec.convertArgs(args...)
+}
+
+// convertArgs converts the named arguments into parameters
+func (c *codec) convertArgs(args ...interface{}) (ps []*rdsdataservice.SqlParameter, err error) {
 	ps = make([]*rdsdataservice.SqlParameter, 0, len(args))
 	for _, arg := range args {
 		named, ok := arg.(sql.NamedArg)
 		if !ok {
-			return nil, ArgErr{ArgErrKindUnsupported, reflect.ValueOf(arg).Type().String()}
+			return nil, ArgErr{Kind: ArgErrKindUnsupported, Type: reflect.ValueOf(arg).Type().String()}
 		}
 
-		field, hint, err := convertArg(named.Value)
+		field, hint, err := c.convertArg(named.Value)
 		if err != nil {
 			return nil, err
 		}
@@ -64,7 +146,16 @@ func ConvertArgs(args ...interface{}) (ps []*rdsdataservice.SqlParameter, err er
 }
 
 // convertArg converts the provided arg into a parameter field and an optional hint
-func convertArg(arg interface{}) (f *rdsdataservice.Field, hint string, err error) {
+func (c *codec) convertArg(arg interface{}) (f *rdsdataservice.Field, hint string, err error) {
+	if enc := c.types.encoder(arg); enc != nil {
+		f, hint, err = enc(arg)
+		if err != nil && !errors.As(err, &ArgErr{}) {
+			return nil, "", ArgErr{Kind: ArgErrKindValuer, Type: reflect.TypeOf(arg).String(), err: err}
+		}
+
+		return f, hint, err
+	}
+
 	f = &rdsdataservice.Field{}
 
 	switch at := arg.(type) {
@@ -84,13 +175,134 @@ func convertArg(arg interface{}) (f *rdsdataservice.Field, hint string, err erro
 		f.BlobValue = cloneBytes(at)
 	case sql.RawBytes:
 		f.BlobValue = at
+	case time.Time:
+		return c.convertArg(TimeArg{at, rdsdataservice.TypeHintTimestamp})
+	case TimeArg:
+		s, err := c.formatTime(at.Time, at.Hint)
+		if err != nil {
+			return nil, "", ArgErr{Kind: ArgErrKindUnsupported, Type: "TimeArg(" + at.Hint + ")"}
+		}
+
+		f.StringValue, hint = aws.String(s), at.Hint
+	case *big.Rat:
+		if at == nil {
+			return c.convertArg(nil)
+		}
+
+		s, err := ratString(at)
+		if err != nil {
+			return nil, "", ArgErr{Kind: ArgErrKindUnsupported, Type: "*big.Rat(" + at.String() + ")"}
+		}
+
+		f.StringValue, hint = aws.String(s), rdsdataservice.TypeHintDecimal
+	case *big.Float:
+		if at == nil {
+			return c.convertArg(nil)
+		}
+
+		f.StringValue, hint = aws.String(at.Text('f', -1)), rdsdataservice.TypeHintDecimal
+	case *big.Int:
+		if at == nil {
+			return c.convertArg(nil)
+		}
+
+		f.StringValue, hint = aws.String(at.String()), rdsdataservice.TypeHintDecimal
+	case DecimalArg:
+		f.StringValue, hint = aws.String(string(at)), rdsdataservice.TypeHintDecimal
+	case UUIDArg:
+		f.StringValue, hint = aws.String(string(at)), typeHintUUID
+	case JSONArg:
+		data, err := json.Marshal(at.V)
+		if err != nil {
+			return nil, "", ArgErr{Kind: ArgErrKindUnsupported, Type: fmt.Sprintf("JSON(%T)", at.V)}
+		}
+
+		f.StringValue, hint = aws.String(string(data)), typeHintJSON
 	default:
+		return c.convertOther(arg)
+	}
+
+	return
+}
+
+var (
+	valuerType        = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// convertOther converts argument types that this package doesn't know about like the default
+// converter of database/sql does: driver.Valuer, pointers and all types with an underlying bool,
+// number or string type. It also supports encoding.TextMarshaler. Anything else is tried as a
+// (multi-dimensional) array.
+func (c *codec) convertOther(arg interface{}) (f *rdsdataservice.Field, hint string, err error) {
+	rv := reflect.ValueOf(arg)
+	nilPtr := rv.Kind() == reflect.Ptr && rv.IsNil()
+
+	switch at := arg.(type) {
+	case driver.Valuer:
+		if nilPtr && rv.Type().Elem().Implements(valuerType) {
+			return c.convertArg(nil) // value receiver that can't be called on a nil pointer
+		}
+
+		v, err := at.Value()
+		if err != nil {
+			return nil, "", ArgErr{Kind: ArgErrKindValuer, Type: rv.Type().String(), err: err}
+		}
 
-		// try it as a multi-dimentsional array they
-		f.ArrayValue, err = convertArrayArg(arg)
+		if !driver.IsValue(v) {
+			return nil, "", ArgErr{Kind: ArgErrKindUnsupported,
+				Type: fmt.Sprintf("%s(%T)", rv.Type(), v)}
+		}
+
+		return c.convertArg(v)
+	case encoding.TextMarshaler:
+		if nilPtr {
+			return c.convertArg(nil)
+		} else if rv.Kind() == reflect.Ptr && rv.Type().Elem().Implements(textMarshalerType) {
+			return c.convertArg(rv.Elem().Interface()) // the value may have its own conversion, e.g. *time.Time
+		}
+
+		text, err := at.MarshalText()
 		if err != nil {
-			return nil, "", err
+			return nil, "", ArgErr{Kind: ArgErrKindValuer, Type: rv.Type().String(), err: err}
 		}
+
+		return c.convertArg(string(text))
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if nilPtr {
+			return c.convertArg(nil)
+		}
+
+		return c.convertArg(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return c.convertArg(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u64 := rv.Uint()
+		if u64 >= 1<<63 {
+			return nil, "", ArgErr{Kind: ArgErrKindUnsupported,
+				Type: fmt.Sprintf("%s(%d)", rv.Type(), u64)}
+		}
+
+		return c.convertArg(int64(u64))
+	case reflect.Float32, reflect.Float64:
+		return c.convertArg(rv.Float())
+	case reflect.Bool:
+		return c.convertArg(rv.Bool())
+	case reflect.String:
+		return c.convertArg(rv.String())
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return c.convertArg(rv.Bytes())
+		}
+	}
+
+	// try it as a multi-dimentsional array
+	f = &rdsdataservice.Field{}
+	if f.ArrayValue, err = convertArrayArg(arg); err != nil {
+		return nil, "", err
 	}
 
 	return
@@ -112,7 +324,7 @@ func convertArrayArg(arg interface{}) (av *rdsdataservice.ArrayValue, err error)
 	default:
 		rv := reflect.ValueOf(arg)
 		if rv.Kind() != reflect.Array && rv.Kind() != reflect.Slice {
-			return nil, ArgErr{ArgErrKindUnsupported, rv.Type().String()}
+			return nil, ArgErr{Kind: ArgErrKindUnsupported, Type: rv.Type().String()}
 		}
 
 		av.ArrayValues = make([]*rdsdataservice.ArrayValue, rv.Len())
@@ -2,11 +2,18 @@ package dasql
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
+	"net"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
 )
 
 func TestConvertArrayArg(t *testing.T) {
@@ -53,28 +60,101 @@ func TestConvertArg(t *testing.T) {
 		{nil, `{IsNull:true}`, "", nil},
 
 		{[]string{"foo", "bar"}, `{ArrayValue:{StringValues:["foo","bar"]}}`, "", nil},
+
+		{time.Date(2020, 1, 2, 3, 4, 5, 6e6, time.UTC), `{StringValue:"2020-01-0203:04:05.006"}`, "TIMESTAMP", nil},
+		{time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600)), `{StringValue:"2020-01-0202:04:05"}`, "TIMESTAMP", nil},
+		{testTimePtr(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), `{StringValue:"2020-01-0203:04:05"}`, "TIMESTAMP", nil},
+		{(*time.Time)(nil), `{IsNull:true}`, "", nil},
+		{TimeArg{time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), "DATE"}, `{StringValue:"2020-01-02"}`, "DATE", nil},
+		{TimeArg{time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), "TIME"}, `{StringValue:"03:04:05"}`, "TIME", nil},
+		{TimeArg{time.Time{}, "FOO"}, ``, "", ArgErr{Kind: ArgErrKindUnsupported, Type: "TimeArg(FOO)"}},
+
+		{Date(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), `{StringValue:"2020-01-02"}`, "DATE", nil},
+		{Time(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), `{StringValue:"03:04:05"}`, "TIME", nil},
+		{Decimal("12.30"), `{StringValue:"12.30"}`, "DECIMAL", nil},
+		{UUID("a8098c1a-f86e-11da-bd1a-00112444be1e"), `{StringValue:"a8098c1a-f86e-11da-bd1a-00112444be1e"}`, "UUID", nil},
+		{JSON(map[string]int{"a": 1}), `{StringValue:"{\"a\":1}"}`, "JSON", nil},
+		{JSON(nil), `{StringValue:"null"}`, "JSON", nil},
+		{JSON(func() {}), ``, "", ArgErr{Kind: ArgErrKindUnsupported, Type: "JSON(func())"}},
+
+		{big.NewRat(5, 4), `{StringValue:"1.25"}`, "DECIMAL", nil},
+		{big.NewRat(1, 3), ``, "", ArgErr{Kind: ArgErrKindUnsupported, Type: "*big.Rat(1/3)"}},
+		{big.NewFloat(1.5), `{StringValue:"1.5"}`, "DECIMAL", nil},
+		{new(big.Int).Lsh(big.NewInt(1), 64), `{StringValue:"18446744073709551616"}`, "DECIMAL", nil},
+		{(*big.Rat)(nil), `{IsNull:true}`, "", nil},
+
+		{sql.NullString{String: "foo", Valid: true}, `{StringValue:"foo"}`, "", nil},
+		{sql.NullString{String: "foo"}, `{IsNull:true}`, "", nil},
+		{sql.NullInt32{Int32: 12, Valid: true}, `{LongValue:12}`, "", nil},
+		{sql.NullInt64{Int64: 12, Valid: true}, `{LongValue:12}`, "", nil},
+		{sql.NullFloat64{Float64: 0.5, Valid: true}, `{DoubleValue:0.5}`, "", nil},
+		{sql.NullBool{Bool: true, Valid: true}, `{BooleanValue:true}`, "", nil},
+		{sql.NullTime{Time: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true}, `{StringValue:"2020-01-0203:04:05"}`, "TIMESTAMP", nil},
+		{sql.NullTime{}, `{IsNull:true}`, "", nil},
+
+		{testMoney(1250), `{StringValue:"12.50"}`, "", nil},
+		{(*testMoney)(nil), `{IsNull:true}`, "", nil},
+		{testValuerErr{}, ``, "", ArgErr{Kind: ArgErrKindValuer, Type: "dasql.testValuerErr"}},
+		{net.ParseIP("10.0.0.1"), `{StringValue:"10.0.0.1"}`, "", nil},
+		{aws.String("foo"), `{StringValue:"foo"}`, "", nil},
+		{(*string)(nil), `{IsNull:true}`, "", nil},
+		{int8(-8), `{LongValue:-8}`, "", nil},
+		{int32(32), `{LongValue:32}`, "", nil},
+		{uint16(16), `{LongValue:16}`, "", nil},
+		{uint64(1 << 63), ``, "", ArgErr{Kind: ArgErrKindUnsupported, Type: "uint64(9223372036854775808)"}},
+		{float32(0.5), `{DoubleValue:0.5}`, "", nil},
+		{testStatus("active"), `{StringValue:"active"}`, "", nil},
+		{json.RawMessage(`{}`), `{BlobValue:<binary>len2}`, "", nil},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			f, h, err := convertArg(c.arg)
+			f, h, err := defaultCodec.convertArg(c.arg)
 			if !errors.Is(err, c.expErr) {
 				t.Fatalf("exp: %v got: %v", c.expErr, err)
 			}
 
+			if c.expErr != nil {
+				return
+			}
+
 			if act := strings.Join(strings.Fields(f.String()), ""); act != c.exp {
 				t.Fatalf("exp: %v got: %v", c.exp, act)
 			}
 
 			if h != c.expHint {
-				t.Fatalf("exp: %v got: %v", c.exp, h)
+				t.Fatalf("exp: %v got: %v", c.expHint, h)
 			}
 		})
 	}
 }
 
+// testMoney is a custom type that is sent to the database as a decimal string
+type testMoney int64
+
+func (m testMoney) Value() (driver.Value, error) {
+	return fmt.Sprintf("%d.%02d", m/100, m%100), nil
+}
+
+type testValuerErr struct{}
+
+func (testValuerErr) Value() (driver.Value, error) { return nil, errors.New("no value") }
+
+type testStatus string
+
+func TestConvertArgValuerErr(t *testing.T) {
+	_, _, err := defaultCodec.convertArg(testValuerErr{})
+	if err == nil || err.Error() != "failed to get the value of argument type dasql.testValuerErr: no value" {
+		t.Fatalf("got: %v", err)
+	}
+
+	if errors.Unwrap(err) == nil {
+		t.Fatalf("got: %v", errors.Unwrap(err))
+	}
+}
+
 func TestConvertArgRefClone(t *testing.T) {
 	t.Run("ref", func(t *testing.T) {
 		arg := sql.RawBytes{0x01}
-		f, _, _ := convertArg(arg)
+		f, _, _ := defaultCodec.convertArg(arg)
 		f.BlobValue[0] = 0x02
 
 		if arg[0] != 0x02 {
@@ -84,7 +164,7 @@ func TestConvertArgRefClone(t *testing.T) {
 
 	t.Run("clone", func(t *testing.T) {
 		arg := []byte{0x01}
-		f, _, _ := convertArg(arg)
+		f, _, _ := defaultCodec.convertArg(arg)
 		f.BlobValue[0] = 0x02
 
 		if arg[0] == 0x02 {
@@ -135,4 +215,15 @@ func TestArgErr(t *testing.T) {
 	if err.Error() != "error while converting argument" {
 		t.Fatalf("got: %v", err.Error())
 	}
+
+	err = ArgErr{Kind: ArgErrKindMissing, Names: []string{"foo", "bar"}}
+	if !strings.Contains(err.Error(), "foo, bar") {
+		t.Fatalf("got: %v", err.Error())
+	}
+
+	if errors.Is(err, ArgErr{Kind: ArgErrKindMissing, Names: []string{"foo"}}) {
+		t.Fatalf("should not be equal")
+	}
 }
+
+func testTimePtr(t time.Time) *time.Time { return &t }
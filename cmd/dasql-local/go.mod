@@ -0,0 +1,12 @@
+module github.com/go-data-api/dasql/cmd/dasql-local
+
+go 1.15
+
+require (
+	github.com/aws/aws-sdk-go v1.35.24
+	github.com/go-data-api/dasql v0.0.0
+	github.com/go-sql-driver/mysql v1.5.0
+	github.com/lib/pq v1.9.0
+)
+
+replace github.com/go-data-api/dasql => ../../
@@ -0,0 +1,98 @@
+// Command dasql-local serves the RDS Data API REST endpoints on a local address. The statements
+// are either run against a local MySQL or Postgres database, or forwarded to the real Data API. This allows
+// the stock AWS SDK (configured with a custom endpoint) and non-Go services to use a local stand-in
+// for the Data API.
+//
+//	dasql-local -mysql "root:pass@(127.0.0.1:3306)/app" \
+//		-resource-arns arn:aws:rds:eu-west-1:123456789012:cluster:app \
+//		-secret-arns arn:aws:secretsmanager:eu-west-1:123456789012:secret:app-AbCdEf
+//
+// It is a module of its own, so the database drivers are not dependencies of the dasql package.
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+	"github.com/go-data-api/dasql"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "dasql-local: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("dasql-local", flag.ContinueOnError)
+	addr := fs.String("addr", "127.0.0.1:8090", "address to serve the Data API on")
+	mysql := fs.String("mysql", "", "DSN of a local MySQL database to run statements against")
+	postgres := fs.String("postgres", "", "DSN of a local Postgres database to run statements against")
+	region := fs.String("forward-region", "", "forward to the real Data API in this region")
+	resources := fs.String("resource-arns", "", "comma separated resource ARNs that are allowed")
+	secrets := fs.String("secret-arns", "", "comma separated secret ARNs that are allowed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *resources == "" || *secrets == "" {
+		return errors.New("-resource-arns and -secret-arns are required")
+	}
+
+	var nset int
+	for _, v := range []string{*mysql, *postgres, *region} {
+		if v != "" {
+			nset++
+		}
+	}
+
+	var da dasql.DA
+	switch {
+	case nset > 1:
+		return errors.New("-mysql, -postgres and -forward-region can't be used together")
+	case *mysql != "":
+		db, err := sql.Open("mysql", *mysql)
+		if err != nil {
+			return fmt.Errorf("failed to open mysql: %w", err)
+		}
+
+		da = dasql.LocalDA(db, dasql.DialectMySQL)
+	case *postgres != "":
+		db, err := sql.Open("postgres", *postgres)
+		if err != nil {
+			return fmt.Errorf("failed to open postgres: %w", err)
+		}
+
+		da = dasql.LocalDA(db, dasql.DialectPostgres)
+	case *region != "":
+		sess, err := session.NewSession(request.WithRetryer(
+			aws.NewConfig().WithRegion(*region),
+			dasql.Retryer{DefaultRetryer: client.DefaultRetryer{NumMaxRetries: 10}}))
+		if err != nil {
+			return fmt.Errorf("failed to setup aws session: %w", err)
+		}
+
+		da = rdsdataservice.New(sess)
+	default:
+		return errors.New("either -mysql, -postgres or -forward-region is required")
+	}
+
+	log.Printf("serving the Data API on http://%s", *addr)
+	return http.ListenAndServe(*addr, newServer(da,
+		strings.Split(*resources, ","),
+		strings.Split(*secrets, ",")))
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+	"github.com/go-data-api/dasql"
+)
+
+// server serves the REST endpoints of the Data API and forwards them to a DA implementation
+type server struct {
+	da        dasql.DA
+	resources map[string]bool
+	secrets   map[string]bool
+}
+
+// newServer creates the handler that only allows access to the resources and secrets provided
+func newServer(da dasql.DA, resources, secrets []string) *server {
+	s := &server{da, make(map[string]bool), make(map[string]bool)}
+	for _, arn := range resources {
+		s.resources[arn] = true
+	}
+
+	for _, arn := range secrets {
+		s.secrets[arn] = true
+	}
+
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, awserr.New(rdsdataservice.ErrCodeBadRequestException,
+			"method not allowed: "+r.Method, nil))
+		return
+	}
+
+	var out interface{}
+	var err error
+	switch r.URL.Path {
+	case "/Execute":
+		out, err = s.execute(r.Context(), r.Body)
+	case "/BeginTransaction":
+		out, err = s.begin(r.Context(), r.Body)
+	case "/CommitTransaction":
+		out, err = s.commit(r.Context(), r.Body)
+	case "/RollbackTransaction":
+		out, err = s.rollback(r.Context(), r.Body)
+	case "/BatchExecute":
+		out, err = s.batch(r.Context(), r.Body)
+	default:
+		err = &rdsdataservice.NotFoundException{
+			Message_: aws.String("unknown operation: " + r.URL.Path)}
+	}
+
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// execute handles the /Execute endpoint
+func (s *server) execute(ctx context.Context, body io.Reader) (interface{}, error) {
+	var req executeRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	in := req.input()
+	if err := s.check(in); err != nil {
+		return nil, err
+	}
+
+	out, err := s.da.ExecuteStatementWithContext(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	return newExecuteResponse(out), nil
+}
+
+// begin handles the /BeginTransaction endpoint
+func (s *server) begin(ctx context.Context, body io.Reader) (interface{}, error) {
+	var req beginRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	in := req.input()
+	if err := s.check(in); err != nil {
+		return nil, err
+	}
+
+	out, err := s.da.BeginTransactionWithContext(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	return &beginResponse{TransactionId: out.TransactionId}, nil
+}
+
+// commit handles the /CommitTransaction endpoint
+func (s *server) commit(ctx context.Context, body io.Reader) (interface{}, error) {
+	var req endRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	in := req.commitInput()
+	if err := s.check(in); err != nil {
+		return nil, err
+	}
+
+	out, err := s.da.CommitTransactionWithContext(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	return &endResponse{TransactionStatus: out.TransactionStatus}, nil
+}
+
+// rollback handles the /RollbackTransaction endpoint
+func (s *server) rollback(ctx context.Context, body io.Reader) (interface{}, error) {
+	var req endRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	in := req.rollbackInput()
+	if err := s.check(in); err != nil {
+		return nil, err
+	}
+
+	out, err := s.da.RollbackTransactionWithContext(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	return &endResponse{TransactionStatus: out.TransactionStatus}, nil
+}
+
+// batch handles the /BatchExecute endpoint
+func (s *server) batch(ctx context.Context, body io.Reader) (interface{}, error) {
+	var req batchRequest
+	if err := decode(body, &req); err != nil {
+		return nil, err
+	}
+
+	in := req.input()
+	if err := s.check(in); err != nil {
+		return nil, err
+	}
+
+	out, err := s.da.BatchExecuteStatementWithContext(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBatchResponse(out), nil
+}
+
+// input is implemented by all the Data API inputs
+type input interface{ Validate() error }
+
+// decode reads the JSON request body into 'req', an empty body is an empty request
+func decode(body io.Reader, req interface{}) error {
+	if err := json.NewDecoder(body).Decode(req); err != nil && !errors.Is(err, io.EOF) {
+		return awserr.New(rdsdataservice.ErrCodeBadRequestException,
+			"failed to decode request body", err)
+	}
+
+	return nil
+}
+
+// check validates the input and checks the resource and secret ARN against the allow-list
+func (s *server) check(in input) error {
+	if err := in.Validate(); err != nil {
+		return awserr.New(rdsdataservice.ErrCodeBadRequestException, err.Error(), nil)
+	}
+
+	res, sec := arns(in)
+	if !s.resources[res] || !s.secrets[sec] {
+		return &rdsdataservice.ForbiddenException{Message_: aws.String(
+			fmt.Sprintf("access to resource '%s' with secret '%s' is not allowed", res, sec))}
+	}
+
+	return nil
+}
+
+// arns returns the resource and secret ARNs of a Data API input
+func arns(in input) (res, sec string) {
+	switch it := in.(type) {
+	case *rdsdataservice.ExecuteStatementInput:
+		return aws.StringValue(it.ResourceArn), aws.StringValue(it.SecretArn)
+	case *rdsdataservice.BeginTransactionInput:
+		return aws.StringValue(it.ResourceArn), aws.StringValue(it.SecretArn)
+	case *rdsdataservice.CommitTransactionInput:
+		return aws.StringValue(it.ResourceArn), aws.StringValue(it.SecretArn)
+	case *rdsdataservice.RollbackTransactionInput:
+		return aws.StringValue(it.ResourceArn), aws.StringValue(it.SecretArn)
+	case *rdsdataservice.BatchExecuteStatementInput:
+		return aws.StringValue(it.ResourceArn), aws.StringValue(it.SecretArn)
+	default:
+		return "", ""
+	}
+}
+
+// writeError writes the error the way the Data API does, so the SDK turns it into the same error
+func writeError(w http.ResponseWriter, err error) {
+	code, msg := rdsdataservice.ErrCodeInternalServerErrorException, err.Error()
+
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		code, msg = aerr.Code(), aerr.Message()
+	}
+
+	status := http.StatusInternalServerError
+	switch code {
+	case rdsdataservice.ErrCodeBadRequestException, rdsdataservice.ErrCodeStatementTimeoutException:
+		status = http.StatusBadRequest
+	case rdsdataservice.ErrCodeForbiddenException:
+		status = http.StatusForbidden
+	case rdsdataservice.ErrCodeNotFoundException:
+		status = http.StatusNotFound
+	case rdsdataservice.ErrCodeServiceUnavailableError:
+		status = http.StatusServiceUnavailable
+	}
+
+	var body bytes.Buffer
+	_ = json.NewEncoder(&body).Encode(struct {
+		Message string `json:"message"`
+	}{msg})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Amzn-Errortype", code)
+	w.WriteHeader(status)
+	_, _ = body.WriteTo(w)
+}
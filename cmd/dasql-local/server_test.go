@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+	"github.com/go-data-api/dasql"
+)
+
+const (
+	testResourceARN = "arn:aws:rds:eu-west-1:123456789012:cluster:app"
+	testSecretARN   = "arn:aws:secretsmanager:eu-west-1:123456789012:secret:app-AbCdEf"
+)
+
+// fakeDA returns canned outputs and remembers the last statement input
+type fakeDA struct {
+	lastESI *rdsdataservice.ExecuteStatementInput
+	nextESE error
+}
+
+func (d *fakeDA) ExecuteStatementWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.ExecuteStatementInput,
+	opts ...request.Option) (*rdsdataservice.ExecuteStatementOutput, error) {
+	d.lastESI = in
+	return &rdsdataservice.ExecuteStatementOutput{
+		Records: [][]*rdsdataservice.Field{{
+			{StringValue: aws.String("foo")},
+			{LongValue: aws.Int64(1)},
+			{BlobValue: []byte{0x01}},
+			{IsNull: aws.Bool(true)},
+			{ArrayValue: &rdsdataservice.ArrayValue{LongValues: aws.Int64Slice([]int64{1, 2})}},
+		}},
+		ColumnMetadata: []*rdsdataservice.ColumnMetadata{{Name: aws.String("a")}},
+	}, d.nextESE
+}
+
+func (d *fakeDA) BeginTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.BeginTransactionInput,
+	opts ...request.Option) (*rdsdataservice.BeginTransactionOutput, error) {
+	return &rdsdataservice.BeginTransactionOutput{TransactionId: aws.String("1234")}, nil
+}
+
+func (d *fakeDA) CommitTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.CommitTransactionInput,
+	opts ...request.Option) (*rdsdataservice.CommitTransactionOutput, error) {
+	return &rdsdataservice.CommitTransactionOutput{}, nil
+}
+
+func (d *fakeDA) RollbackTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.RollbackTransactionInput,
+	opts ...request.Option) (*rdsdataservice.RollbackTransactionOutput, error) {
+	return &rdsdataservice.RollbackTransactionOutput{}, nil
+}
+
+func (d *fakeDA) BatchExecuteStatementWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.BatchExecuteStatementInput,
+	opts ...request.Option) (*rdsdataservice.BatchExecuteStatementOutput, error) {
+	return &rdsdataservice.BatchExecuteStatementOutput{UpdateResults: []*rdsdataservice.UpdateResult{
+		{GeneratedFields: []*rdsdataservice.Field{{LongValue: aws.Int64(42)}}}}}, nil
+}
+
+// newClient returns a Data API client from the official SDK that talks to a test server
+func newClient(t *testing.T, da dasql.DA) *rdsdataservice.RDSDataService {
+	srv := httptest.NewServer(newServer(da, []string{testResourceARN}, []string{testSecretARN}))
+	t.Cleanup(srv.Close)
+
+	return rdsdataservice.New(session.Must(session.NewSession(aws.NewConfig().
+		WithEndpoint(srv.URL).
+		WithRegion("eu-west-1").
+		WithMaxRetries(0).
+		WithCredentials(credentials.NewStaticCredentials("id", "secret", "")))))
+}
+
+func TestServerStatements(t *testing.T) {
+	da, ctx := &fakeDA{}, context.Background()
+	db := dasql.New(newClient(t, da), testResourceARN, testSecretARN)
+
+	rows, err := db.Query(ctx, `SELECT * FROM foo WHERE a = :a`, sql.Named("a", "bar"))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.Parameters[0].Value.StringValue); act != "bar" {
+		t.Fatalf("got: %v", act)
+	}
+
+	var s string
+	var n int64
+	var b []byte
+	var ns sql.NullString
+	var ids []int64
+	for rows.Next() {
+		if err = rows.Scan(&s, &n, &b, &ns, &ids); err != nil {
+			t.Fatalf("got: %v", err)
+		}
+	}
+
+	if s != "foo" || n != 1 || len(b) != 1 || b[0] != 0x01 || ns.Valid || len(ids) != 2 || ids[1] != 2 {
+		t.Fatalf("got: %v %v %v %v %v", s, n, b, ns, ids)
+	}
+
+	if cols, _ := dasql.Columns(rows); len(cols) != 1 || cols[0] != "a" {
+		t.Fatalf("got: %v", cols)
+	}
+
+	tx, err := db.Tx(ctx)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	res, err := tx.ExecBatch(ctx, dasql.NewBatch(`INSERT INTO foo (a) VALUES (:a)`).
+		Exec(sql.Named("a", 1)))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if id, _ := res[0].LastInsertId(); id != 42 {
+		t.Fatalf("got: %v", id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if tx, err = db.Tx(ctx); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = tx.Rollback(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	da, ctx := &fakeDA{}, context.Background()
+	client := newClient(t, da)
+
+	_, err := dasql.New(client, testResourceARN, "arn:aws:secretsmanager:other").Exec(ctx, `SELECT 1`)
+	var ferr *rdsdataservice.ForbiddenException
+	if !errors.As(err, &ferr) {
+		t.Fatalf("got: %T %v", err, err)
+	}
+
+	da.nextESE = &rdsdataservice.BadRequestException{Message_: aws.String("syntax error")}
+	_, err = dasql.New(client, testResourceARN, testSecretARN).Exec(ctx, `SELECT`)
+
+	var brerr *rdsdataservice.BadRequestException
+	if !errors.As(err, &brerr) || brerr.Message() != "syntax error" || brerr.StatusCode() != 400 {
+		t.Fatalf("got: %T %v", err, err)
+	}
+
+	da.nextESE = errors.New("boom")
+	_, err = dasql.New(client, testResourceARN, testSecretARN).Exec(ctx, `SELECT`)
+
+	var ierr *rdsdataservice.InternalServerErrorException
+	if !errors.As(err, &ierr) {
+		t.Fatalf("got: %T %v", err, err)
+	}
+}
+
+func TestServerRequests(t *testing.T) {
+	srv := newServer(&fakeDA{}, []string{testResourceARN}, []string{testSecretARN})
+	for _, c := range []struct {
+		method, path, body string
+		expStatus          int
+		expType            string
+	}{
+		{http.MethodGet, "/Execute", ``, http.StatusBadRequest, "BadRequestException"},
+		{http.MethodPost, "/Foo", `{}`, http.StatusNotFound, "NotFoundException"},
+		{http.MethodPost, "/Execute", `{`, http.StatusBadRequest, "BadRequestException"},
+		{http.MethodPost, "/Execute", `{}`, http.StatusBadRequest, "BadRequestException"},
+		{http.MethodPost, "/Execute", `{"resourceArn":"` + testResourceARN + `","secretArn":"` +
+			testSecretARN + `","sql":"SELECT 1"}`, http.StatusOK, ""},
+	} {
+		t.Run(c.method+c.path+c.body, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, httptest.NewRequest(c.method, c.path, strings.NewReader(c.body)))
+
+			if rec.Code != c.expStatus {
+				t.Fatalf("exp: %v got: %v", c.expStatus, rec.Code)
+			}
+
+			if act := rec.Header().Get("X-Amzn-Errortype"); act != c.expType {
+				t.Fatalf("exp: %v got: %v", c.expType, act)
+			}
+		})
+	}
+}
+
+func TestRunFlags(t *testing.T) {
+	for _, c := range []struct {
+		args   []string
+		expErr string
+	}{
+		{[]string{}, "are required"},
+		{[]string{"-resource-arns", "a", "-secret-arns", "b"}, "either"},
+		{[]string{"-resource-arns", "a", "-secret-arns", "b", "-mysql", "x",
+			"-forward-region", "y"}, "together"},
+		{[]string{"-resource-arns", "a", "-secret-arns", "b", "-mysql", "x",
+			"-postgres", "y"}, "together"},
+		{[]string{"-foo"}, "not defined"},
+	} {
+		err := run(c.args)
+		if err == nil || !strings.Contains(err.Error(), c.expErr) {
+			t.Fatalf("exp: %v got: %v", c.expErr, err)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// The types below describe the JSON bodies of the Data API REST endpoints. They are declared here
+// instead of relying on the SDK's (internal) protocol package, which has no compatibility promise.
+
+// field is the JSON form of rdsdataservice.Field
+type field struct {
+	IsNull       *bool       `json:"isNull,omitempty"`
+	BooleanValue *bool       `json:"booleanValue,omitempty"`
+	LongValue    *int64      `json:"longValue,omitempty"`
+	DoubleValue  *float64    `json:"doubleValue,omitempty"`
+	StringValue  *string     `json:"stringValue,omitempty"`
+	BlobValue    []byte      `json:"blobValue,omitempty"`
+	ArrayValue   *arrayValue `json:"arrayValue,omitempty"`
+}
+
+// arrayValue is the JSON form of rdsdataservice.ArrayValue
+type arrayValue struct {
+	BooleanValues *[]bool        `json:"booleanValues,omitempty"`
+	LongValues    *[]int64       `json:"longValues,omitempty"`
+	DoubleValues  *[]float64     `json:"doubleValues,omitempty"`
+	StringValues  *[]string      `json:"stringValues,omitempty"`
+	ArrayValues   *[]*arrayValue `json:"arrayValues,omitempty"`
+}
+
+// sqlParameter is the JSON form of rdsdataservice.SqlParameter
+type sqlParameter struct {
+	Name     *string `json:"name,omitempty"`
+	TypeHint *string `json:"typeHint,omitempty"`
+	Value    *field  `json:"value,omitempty"`
+}
+
+// columnMetadata is the JSON form of rdsdataservice.ColumnMetadata
+type columnMetadata struct {
+	ArrayBaseColumnType *int64  `json:"arrayBaseColumnType,omitempty"`
+	IsAutoIncrement     *bool   `json:"isAutoIncrement,omitempty"`
+	IsCaseSensitive     *bool   `json:"isCaseSensitive,omitempty"`
+	IsCurrency          *bool   `json:"isCurrency,omitempty"`
+	IsSigned            *bool   `json:"isSigned,omitempty"`
+	Label               *string `json:"label,omitempty"`
+	Name                *string `json:"name,omitempty"`
+	Nullable            *int64  `json:"nullable,omitempty"`
+	Precision           *int64  `json:"precision,omitempty"`
+	Scale               *int64  `json:"scale,omitempty"`
+	SchemaName          *string `json:"schemaName,omitempty"`
+	TableName           *string `json:"tableName,omitempty"`
+	Type                *int64  `json:"type,omitempty"`
+	TypeName            *string `json:"typeName,omitempty"`
+}
+
+// resultSetOptions is the JSON form of rdsdataservice.ResultSetOptions
+type resultSetOptions struct {
+	DecimalReturnType *string `json:"decimalReturnType,omitempty"`
+}
+
+// executeRequest is the body of the /Execute endpoint
+type executeRequest struct {
+	ResourceArn           *string           `json:"resourceArn"`
+	SecretArn             *string           `json:"secretArn"`
+	Sql                   *string           `json:"sql"`
+	Database              *string           `json:"database"`
+	Schema                *string           `json:"schema"`
+	TransactionId         *string           `json:"transactionId"`
+	Parameters            []*sqlParameter   `json:"parameters"`
+	IncludeResultMetadata *bool             `json:"includeResultMetadata"`
+	ContinueAfterTimeout  *bool             `json:"continueAfterTimeout"`
+	ResultSetOptions      *resultSetOptions `json:"resultSetOptions"`
+}
+
+// executeResponse is the body that the /Execute endpoint responds with
+type executeResponse struct {
+	ColumnMetadata         []*columnMetadata `json:"columnMetadata,omitempty"`
+	GeneratedFields        []*field          `json:"generatedFields,omitempty"`
+	NumberOfRecordsUpdated *int64            `json:"numberOfRecordsUpdated,omitempty"`
+	Records                [][]*field        `json:"records,omitempty"`
+}
+
+// beginRequest is the body of the /BeginTransaction endpoint
+type beginRequest struct {
+	ResourceArn *string `json:"resourceArn"`
+	SecretArn   *string `json:"secretArn"`
+	Database    *string `json:"database"`
+	Schema      *string `json:"schema"`
+}
+
+// beginResponse is the body that the /BeginTransaction endpoint responds with
+type beginResponse struct {
+	TransactionId *string `json:"transactionId,omitempty"`
+}
+
+// endRequest is the body of the /CommitTransaction and /RollbackTransaction endpoints
+type endRequest struct {
+	ResourceArn   *string `json:"resourceArn"`
+	SecretArn     *string `json:"secretArn"`
+	TransactionId *string `json:"transactionId"`
+}
+
+// endResponse is the body that the /CommitTransaction and /RollbackTransaction endpoints respond with
+type endResponse struct {
+	TransactionStatus *string `json:"transactionStatus,omitempty"`
+}
+
+// batchRequest is the body of the /BatchExecute endpoint
+type batchRequest struct {
+	ResourceArn   *string           `json:"resourceArn"`
+	SecretArn     *string           `json:"secretArn"`
+	Sql           *string           `json:"sql"`
+	Database      *string           `json:"database"`
+	Schema        *string           `json:"schema"`
+	TransactionId *string           `json:"transactionId"`
+	ParameterSets [][]*sqlParameter `json:"parameterSets"`
+}
+
+// updateResult is the JSON form of rdsdataservice.UpdateResult
+type updateResult struct {
+	GeneratedFields []*field `json:"generatedFields,omitempty"`
+}
+
+// batchResponse is the body that the /BatchExecute endpoint responds with
+type batchResponse struct {
+	UpdateResults []*updateResult `json:"updateResults,omitempty"`
+}
+
+// input converts the request into the SDK's input
+func (r *executeRequest) input() *rdsdataservice.ExecuteStatementInput {
+	in := &rdsdataservice.ExecuteStatementInput{
+		ResourceArn:           r.ResourceArn,
+		SecretArn:             r.SecretArn,
+		Sql:                   r.Sql,
+		Database:              r.Database,
+		Schema:                r.Schema,
+		TransactionId:         r.TransactionId,
+		Parameters:            sdkParameters(r.Parameters),
+		IncludeResultMetadata: r.IncludeResultMetadata,
+		ContinueAfterTimeout:  r.ContinueAfterTimeout,
+	}
+
+	if r.ResultSetOptions != nil {
+		in.ResultSetOptions = &rdsdataservice.ResultSetOptions{
+			DecimalReturnType: r.ResultSetOptions.DecimalReturnType}
+	}
+
+	return in
+}
+
+// input converts the request into the SDK's input
+func (r *beginRequest) input() *rdsdataservice.BeginTransactionInput {
+	return &rdsdataservice.BeginTransactionInput{
+		ResourceArn: r.ResourceArn, SecretArn: r.SecretArn, Database: r.Database, Schema: r.Schema}
+}
+
+// commitInput converts the request into the SDK's input for a commit
+func (r *endRequest) commitInput() *rdsdataservice.CommitTransactionInput {
+	return &rdsdataservice.CommitTransactionInput{
+		ResourceArn: r.ResourceArn, SecretArn: r.SecretArn, TransactionId: r.TransactionId}
+}
+
+// rollbackInput converts the request into the SDK's input for a rollback
+func (r *endRequest) rollbackInput() *rdsdataservice.RollbackTransactionInput {
+	return &rdsdataservice.RollbackTransactionInput{
+		ResourceArn: r.ResourceArn, SecretArn: r.SecretArn, TransactionId: r.TransactionId}
+}
+
+// input converts the request into the SDK's input
+func (r *batchRequest) input() *rdsdataservice.BatchExecuteStatementInput {
+	in := &rdsdataservice.BatchExecuteStatementInput{
+		ResourceArn:   r.ResourceArn,
+		SecretArn:     r.SecretArn,
+		Sql:           r.Sql,
+		Database:      r.Database,
+		Schema:        r.Schema,
+		TransactionId: r.TransactionId,
+	}
+
+	for _, params := range r.ParameterSets {
+		in.ParameterSets = append(in.ParameterSets, sdkParameters(params))
+	}
+
+	return in
+}
+
+// newExecuteResponse converts the SDK's output into a response
+func newExecuteResponse(out *rdsdataservice.ExecuteStatementOutput) *executeResponse {
+	resp := &executeResponse{
+		GeneratedFields:        wireFields(out.GeneratedFields),
+		NumberOfRecordsUpdated: out.NumberOfRecordsUpdated,
+	}
+
+	for _, cm := range out.ColumnMetadata {
+		resp.ColumnMetadata = append(resp.ColumnMetadata, &columnMetadata{
+			ArrayBaseColumnType: cm.ArrayBaseColumnType,
+			IsAutoIncrement:     cm.IsAutoIncrement,
+			IsCaseSensitive:     cm.IsCaseSensitive,
+			IsCurrency:          cm.IsCurrency,
+			IsSigned:            cm.IsSigned,
+			Label:               cm.Label,
+			Name:                cm.Name,
+			Nullable:            cm.Nullable,
+			Precision:           cm.Precision,
+			Scale:               cm.Scale,
+			SchemaName:          cm.SchemaName,
+			TableName:           cm.TableName,
+			Type:                cm.Type,
+			TypeName:            cm.TypeName,
+		})
+	}
+
+	for _, rec := range out.Records {
+		resp.Records = append(resp.Records, wireFields(rec))
+	}
+
+	return resp
+}
+
+// newBatchResponse converts the SDK's output into a response
+func newBatchResponse(out *rdsdataservice.BatchExecuteStatementOutput) *batchResponse {
+	resp := &batchResponse{}
+	for _, ur := range out.UpdateResults {
+		resp.UpdateResults = append(resp.UpdateResults,
+			&updateResult{GeneratedFields: wireFields(ur.GeneratedFields)})
+	}
+
+	return resp
+}
+
+// sdkParameters converts the parameters of a request into the SDK's parameters
+func sdkParameters(params []*sqlParameter) []*rdsdataservice.SqlParameter {
+	if params == nil {
+		return nil
+	}
+
+	sps := make([]*rdsdataservice.SqlParameter, len(params))
+	for i, p := range params {
+		sps[i] = &rdsdataservice.SqlParameter{Name: p.Name, TypeHint: p.TypeHint}
+		if p.Value != nil {
+			sps[i].Value = p.Value.sdk()
+		}
+	}
+
+	return sps
+}
+
+// wireFields converts the SDK's fields into fields of a response
+func wireFields(fs []*rdsdataservice.Field) []*field {
+	if fs == nil {
+		return nil
+	}
+
+	wfs := make([]*field, len(fs))
+	for i, f := range fs {
+		wfs[i] = &field{
+			IsNull:       f.IsNull,
+			BooleanValue: f.BooleanValue,
+			LongValue:    f.LongValue,
+			DoubleValue:  f.DoubleValue,
+			StringValue:  f.StringValue,
+			BlobValue:    f.BlobValue,
+		}
+
+		if f.ArrayValue != nil {
+			wfs[i].ArrayValue = wireArray(f.ArrayValue)
+		}
+	}
+
+	return wfs
+}
+
+// sdk converts the field of a request into the SDK's field
+func (f *field) sdk() *rdsdataservice.Field {
+	sf := &rdsdataservice.Field{
+		IsNull:       f.IsNull,
+		BooleanValue: f.BooleanValue,
+		LongValue:    f.LongValue,
+		DoubleValue:  f.DoubleValue,
+		StringValue:  f.StringValue,
+		BlobValue:    f.BlobValue,
+	}
+
+	if f.ArrayValue != nil {
+		sf.ArrayValue = f.ArrayValue.sdk()
+	}
+
+	return sf
+}
+
+// sdk converts the array value of a request into the SDK's array value
+func (av *arrayValue) sdk() *rdsdataservice.ArrayValue {
+	sav := &rdsdataservice.ArrayValue{}
+	switch {
+	case av.BooleanValues != nil:
+		sav.BooleanValues = aws.BoolSlice(*av.BooleanValues)
+	case av.LongValues != nil:
+		sav.LongValues = aws.Int64Slice(*av.LongValues)
+	case av.DoubleValues != nil:
+		sav.DoubleValues = aws.Float64Slice(*av.DoubleValues)
+	case av.StringValues != nil:
+		sav.StringValues = aws.StringSlice(*av.StringValues)
+	case av.ArrayValues != nil:
+		sav.ArrayValues = make([]*rdsdataservice.ArrayValue, len(*av.ArrayValues))
+		for i, av := range *av.ArrayValues {
+			sav.ArrayValues[i] = av.sdk()
+		}
+	}
+
+	return sav
+}
+
+// wireArray converts the SDK's array value into the one of a response
+func wireArray(sav *rdsdataservice.ArrayValue) *arrayValue {
+	av := &arrayValue{}
+	switch {
+	case sav.BooleanValues != nil:
+		vs := aws.BoolValueSlice(sav.BooleanValues)
+		av.BooleanValues = &vs
+	case sav.LongValues != nil:
+		vs := aws.Int64ValueSlice(sav.LongValues)
+		av.LongValues = &vs
+	case sav.DoubleValues != nil:
+		vs := aws.Float64ValueSlice(sav.DoubleValues)
+		av.DoubleValues = &vs
+	case sav.StringValues != nil:
+		vs := aws.StringValueSlice(sav.StringValues)
+		av.StringValues = &vs
+	case sav.ArrayValues != nil:
+		vs := make([]*arrayValue, len(sav.ArrayValues))
+		for i, v := range sav.ArrayValues {
+			vs[i] = wireArray(v)
+		}
+		av.ArrayValues = &vs
+	}
+
+	return av
+}
@@ -0,0 +1,60 @@
+package dasql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// layouts that the Data API uses to format date and time values
+const (
+	dateLayout      = "2006-01-02"
+	timeLayout      = "15:04:05.999"
+	timestampLayout = "2006-01-02 15:04:05.999"
+)
+
+// codec converts Go values into Data API fields and back. It holds the configuration of the DB
+// that affects these conversions.
+type codec struct {
+	loc   *time.Location // location of date and time values without a zone
+	nulls NullPolicy     // how NULL fields are scanned into destinations that can't hold them
+	types *TypeRegistry  // custom conversions, if any
+}
+
+// defaultCodec is used by the package level functions and when the DB isn't configured otherwise
+var defaultCodec = &codec{loc: time.UTC}
+
+// formatTime formats 't' in the codec's location with the layout for the type hint
+func (c *codec) formatTime(t time.Time, hint string) (string, error) {
+	t = t.In(c.loc)
+	switch hint {
+	case rdsdataservice.TypeHintTimestamp:
+		return t.Format(timestampLayout), nil
+	case rdsdataservice.TypeHintDate:
+		return t.Format(dateLayout), nil
+	case rdsdataservice.TypeHintTime:
+		return t.Format(timeLayout), nil
+	default:
+		return "", fmt.Errorf("unsupported type hint for time: '%s'", hint)
+	}
+}
+
+// parseTime parses a date, time or timestamp as returned by the Data API. Values without a zone
+// are parsed in the codec's location.
+func (c *codec) parseTime(s string) (t time.Time, err error) {
+	for _, layout := range []string{
+		timestampLayout,
+		timestampLayout + "Z07",
+		timestampLayout + "Z07:00",
+		dateLayout,
+		timeLayout,
+		time.RFC3339Nano,
+	} {
+		if t, err = time.ParseInLocation(layout, s, c.loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return t, fmt.Errorf("failed to parse '%s' as a date, time or timestamp", s)
+}
@@ -0,0 +1,90 @@
+package dasql
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestParseTime(t *testing.T) {
+	ams := time.FixedZone("CET", 3600)
+	for i, c := range []struct {
+		s   string
+		loc *time.Location
+		exp time.Time
+	}{
+		{"2020-01-02 03:04:05", time.UTC, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"2020-01-02 03:04:05.1", time.UTC, time.Date(2020, 1, 2, 3, 4, 5, 1e8, time.UTC)},
+		{"2020-01-02 03:04:05.123456", time.UTC, time.Date(2020, 1, 2, 3, 4, 5, 123456e3, time.UTC)},
+		{"2020-01-02 03:04:05", ams, time.Date(2020, 1, 2, 2, 4, 5, 0, time.UTC)},
+		{"2020-01-02 03:04:05+02", ams, time.Date(2020, 1, 2, 1, 4, 5, 0, time.UTC)},
+		{"2020-01-02 03:04:05+02:00", time.UTC, time.Date(2020, 1, 2, 1, 4, 5, 0, time.UTC)},
+		{"2020-01-02T03:04:05Z", ams, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"2020-01-02", time.UTC, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"03:04:05", time.UTC, time.Date(0, 1, 1, 3, 4, 5, 0, time.UTC)},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			act, err := (&codec{loc: c.loc}).parseTime(c.s)
+			if err != nil || !act.Equal(c.exp) {
+				t.Fatalf("exp: %v got: %v (%v)", c.exp, act, err)
+			}
+		})
+	}
+
+	if _, err := defaultCodec.parseTime("2020-13-01"); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestDBLocation(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{
+		Records: [][]*rdsdataservice.Field{{{StringValue: aws.String("2020-01-02 03:04:05")}}},
+	}}, context.Background()
+
+	ams := time.FixedZone("CET", 3600)
+	db := New(da, "", "", WithLocation(ams))
+	rows, err := db.Query(ctx, `SELECT created_at FROM foo WHERE created_at > :t`,
+		sql.Named("t", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.Parameters[0].Value.StringValue); act != "2020-01-01 01:00:00" {
+		t.Fatalf("got: %v", act)
+	}
+
+	if act := aws.StringValue(da.lastESI.Parameters[0].TypeHint); act != "TIMESTAMP" {
+		t.Fatalf("got: %v", act)
+	}
+
+	var ct time.Time
+	for rows.Next() {
+		if err = rows.Scan(&ct); err != nil {
+			t.Fatalf("got: %v", err)
+		}
+	}
+
+	if exp := time.Date(2020, 1, 2, 2, 4, 5, 0, time.UTC); !ct.Equal(exp) || ct.Location() != ams {
+		t.Fatalf("exp: %v got: %v", exp, ct)
+	}
+
+	// the default location is not affected by the option
+	if defaultCodec.loc != time.UTC {
+		t.Fatalf("got: %v", defaultCodec.loc)
+	}
+
+	// a nil location is treated as UTC instead of panicking on the first time value
+	db = New(da, "", "", WithLocation(nil))
+	if _, err = db.Exec(ctx, `SELECT :t`, sql.Named("t", time.Now())); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if db.codec.loc != time.UTC {
+		t.Fatalf("got: %v", db.codec.loc)
+	}
+}
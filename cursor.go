@@ -0,0 +1,128 @@
+package dasql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+)
+
+// QueryCursor executes the query through a server-side cursor, so the result is streamed instead
+// of being returned at once: it declares the cursor and fetches 'fetchSize' rows each time Next
+// is called on the last row that was fetched. Errors while fetching are returned by the Err
+// method of the rows, Close closes the cursor. This is only supported by Postgres, so the DB must be
+// configured with WithDialect(DialectPostgres).
+func (tx *daTx) QueryCursor(
+	ctx context.Context, q string, fetchSize int, args ...interface{},
+) (Rows, error) {
+	if tx.isDone() {
+		return nil, ErrTxDone
+	}
+
+	if tx.db.dialect != DialectPostgres {
+		return nil, errors.New("dasql: cursors are only supported by Postgres, configure the DB " +
+			"with WithDialect(DialectPostgres)")
+	}
+
+	if fetchSize < 1 {
+		return nil, fmt.Errorf("dasql: fetch size must be larger than zero, got: %d", fetchSize)
+	}
+
+	name := tx.cursorName()
+	if _, err := tx.db.execStatement(ctx, tx.id, false,
+		"DECLARE "+name+" NO SCROLL CURSOR FOR "+trimStatement(q), args...); err != nil {
+		return nil, err
+	}
+
+	r := &cursorRows{ctx: ctx, tx: tx, name: name, size: fetchSize}
+	if err := r.fetch(); err != nil {
+		r.Close() // the cursor would stay open until the transaction ends, the fetch error is returned
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// cursorName returns the name for the next cursor of the transaction. It is numbered so the
+// statements are the same each time, and a recorded session can be replayed. A transaction that
+// is resumed with DB.ResumeTx numbers its cursors from one again, so the cursors should be closed
+// before it is handed off.
+func (tx *daTx) cursorName() string {
+	return "dasql_cursor_" + strconv.FormatUint(uint64(atomic.AddUint32(&tx.cursors, 1)), 10)
+}
+
+// cursorRows implements the Rows interface by fetching from a server-side cursor when needed
+type cursorRows struct {
+	*daRows
+
+	ctx  context.Context
+	tx   *daTx
+	name string
+	size int
+
+	done   bool // all rows have been fetched
+	closed bool
+	err    error
+}
+
+// fetch replaces the current rows with the next ones from the cursor
+func (r *cursorRows) fetch() error {
+	var off int
+	if r.daRows != nil {
+		off = r.off + len(r.recs)
+	}
+
+	out, err := r.tx.db.execStatement(r.ctx, r.tx.id, true,
+		"FETCH FORWARD "+strconv.Itoa(r.size)+" FROM "+r.name)
+	if err != nil {
+		return err
+	}
+
+	r.done = len(out.Records) < r.size
+	r.daRows = &daRows{out.Records, -1, out.ColumnMetadata, r.tx.db.mapper, r.tx.db.codec, off}
+	return nil
+}
+
+// Next prepares the next row for scanning, it fetches from the cursor if needed
+func (r *cursorRows) Next() bool {
+	if r.daRows.Next() {
+		return true
+	}
+
+	if r.done || r.closed || r.err != nil {
+		return false
+	}
+
+	if r.err = r.fetch(); r.err != nil {
+		return false
+	}
+
+	return r.daRows.Next()
+}
+
+// Err returns the error that was encountered while fetching from the cursor, if any
+func (r *cursorRows) Err() error { return r.err }
+
+// Close closes the cursor, it is safe to call more than once. The cursor is already closed when
+// the transaction has ended, so nothing is executed then.
+func (r *cursorRows) Close() error {
+	if r.closed {
+		return nil
+	}
+
+	r.closed = true
+	if r.daRows != nil {
+		r.recs = nil
+	}
+
+	if r.tx.isDone() {
+		return nil
+	}
+
+	if _, err := r.tx.db.execStatement(r.ctx, r.tx.id, false, "CLOSE "+r.name); err != nil {
+		return err
+	}
+
+	return nil
+}
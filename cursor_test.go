@@ -0,0 +1,171 @@
+package dasql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// cursorDA is a stub that returns 'n' rows from a cursor
+type cursorDA struct {
+	stubDA
+	n, pos   int
+	sqls     []string
+	fetchErr error // returned by every fetch, if set
+}
+
+func (da *cursorDA) ExecuteStatementWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.ExecuteStatementInput,
+	opts ...request.Option) (*rdsdataservice.ExecuteStatementOutput, error) {
+	q := aws.StringValue(in.Sql)
+	da.sqls = append(da.sqls, q)
+	if aws.StringValue(in.TransactionId) != "1234" {
+		return nil, errors.New("not in the transaction")
+	}
+
+	var size int
+	out := &rdsdataservice.ExecuteStatementOutput{
+		ColumnMetadata: []*rdsdataservice.ColumnMetadata{{Name: aws.String("id")}}}
+	if _, err := fmt.Sscanf(q, "FETCH FORWARD %d", &size); err != nil {
+		return out, nil
+	} else if da.fetchErr != nil {
+		return nil, da.fetchErr
+	}
+
+	for ; size > 0 && da.pos < da.n; size-- {
+		out.Records = append(out.Records, []*rdsdataservice.Field{{LongValue: aws.Int64(int64(da.pos))}})
+		da.pos++
+	}
+
+	return out, nil
+}
+
+func TestTxQueryCursor(t *testing.T) {
+	da, ctx := &cursorDA{n: 5}, context.Background()
+	tx := newTx(ctx, "1234", New(da, "", "", WithDialect(DialectPostgres)))
+
+	rows, err := QueryCursor(ctx, tx, `SELECT id FROM foo WHERE id > $1;`, 2, 0)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	name := "dasql_cursor_1"
+	if exp := "DECLARE " + name + " NO SCROLL CURSOR FOR SELECT id FROM foo WHERE id > :p1"; da.sqls[0] != exp {
+		t.Fatalf("exp: %v got: %v", exp, da.sqls[0])
+	}
+
+	if exp := "FETCH FORWARD 2 FROM " + name; da.sqls[1] != exp {
+		t.Fatalf("exp: %v got: %v", exp, da.sqls[1])
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			t.Fatalf("got: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if RowsErr(rows) != nil || fmt.Sprint(ids) != "[0 1 2 3 4]" {
+		t.Fatalf("got: %v %v", ids, RowsErr(rows))
+	}
+
+	if err = rows.Close(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = rows.Close(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var stmts []string
+	for _, q := range da.sqls {
+		stmts = append(stmts, strings.Fields(q)[0])
+	}
+
+	if act := strings.Join(stmts, ","); act != "DECLARE,FETCH,FETCH,FETCH,CLOSE" {
+		t.Fatalf("got: %v", act)
+	}
+
+	if exp := "CLOSE " + name; da.sqls[4] != exp {
+		t.Fatalf("exp: %v got: %v", exp, da.sqls[4])
+	}
+}
+
+func TestTxQueryCursorErrors(t *testing.T) {
+	da, ctx := &cursorDA{n: 5}, context.Background()
+
+	tx := newTx(ctx, "1234", New(da, "", "", WithDialect(DialectMySQL)))
+	if _, err := QueryCursor(ctx, tx, `SELECT id FROM foo`, 2); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	tx = newTx(ctx, "1234", New(da, "", ""))
+	if _, err := QueryCursor(ctx, tx, `SELECT id FROM foo`, 2); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	tx = newTx(ctx, "1234", New(da, "", "", WithDialect(DialectPostgres)))
+	if _, err := QueryCursor(ctx, tx, `SELECT id FROM foo`, 0); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	rows, err := QueryCursor(ctx, tx, `SELECT id FROM foo`, 2)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	rows.(*cursorRows).tx = newTx(ctx, "4321", tx.db)
+	rows.Next()
+	rows.Next()
+	if rows.Next() || RowsErr(rows) == nil {
+		t.Fatalf("got: %v", RowsErr(rows))
+	}
+
+	if err = rows.Close(); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	// the cursor is closed if the first fetch fails
+	da.sqls, da.fetchErr = nil, errors.New("fetch failed")
+	if _, err = QueryCursor(ctx, tx, `SELECT id FROM foo`, 2); !errors.Is(err, da.fetchErr) {
+		t.Fatalf("got: %v", err)
+	}
+
+	if len(da.sqls) != 3 || da.sqls[2] != "CLOSE dasql_cursor_2" {
+		t.Fatalf("got: %v", da.sqls)
+	}
+}
+
+func TestTxQueryCursorCommitted(t *testing.T) {
+	da, ctx := &cursorDA{n: 5}, context.Background()
+	da.nextCTO = &rdsdataservice.CommitTransactionOutput{}
+	tx := newTx(ctx, "1234", New(da, "", "", WithDialect(DialectPostgres)))
+
+	rows, err := QueryCursor(ctx, tx, `SELECT id FROM foo`, 2)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	// the cursor was closed when the transaction ended
+	if err = rows.Close(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if len(da.sqls) != 2 || strings.HasPrefix(da.sqls[1], "CLOSE") {
+		t.Fatalf("got: %v", da.sqls)
+	}
+}
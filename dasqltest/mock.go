@@ -0,0 +1,617 @@
+// Package dasqltest provides utilities for testing code that uses the dasql package without a
+// real Data API.
+package dasqltest
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+	"github.com/go-data-api/dasql"
+)
+
+var _ dasql.DA = &MockDA{}
+
+// MockDA implements the DA interface by checking every call against a list of expectations that
+// are setup by the test. By default the calls must happen in the order of the expectations.
+type MockDA struct {
+	mu      sync.Mutex
+	ordered bool
+	exps    []expectation
+	ntx     int
+	conv    *dasql.DB
+}
+
+// New creates a mock that expects calls in the order of the expectations. Expected arguments and
+// rows are converted into fields with the options provided, pass the same conversion options
+// (location, type registry) as the DB under test so they are converted the same way.
+func New(opts ...dasql.Option) *MockDA {
+	return &MockDA{ordered: true, conv: dasql.New(nil, "", "", opts...)}
+}
+
+// MatchExpectationsInOrder configures whether the calls must happen in the order of expectations
+func (m *MockDA) MatchExpectationsInOrder(b bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ordered = b
+}
+
+// ExpectationsWereMet returns an error if any of the expectations has not been triggered
+func (m *MockDA) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var unmet []string
+	for _, e := range m.exps {
+		if !e.triggered() {
+			unmet = append(unmet, e.String())
+		}
+	}
+
+	if len(unmet) > 0 {
+		return fmt.Errorf("dasqltest: there are unmet expectations:\n  %s",
+			strings.Join(unmet, "\n  "))
+	}
+
+	return nil
+}
+
+// ExpectExec expects a statement that matches the regular expression 'sqlRe' to be executed
+func (m *MockDA) ExpectExec(sqlRe string) *ExpectedExec {
+	e := &ExpectedExec{expectedStatement: expectedStatement{re: regexp.MustCompile(sqlRe)}}
+	m.expect(e)
+	return e
+}
+
+// ExpectQuery expects a query that matches the regular expression 'sqlRe' to be executed
+func (m *MockDA) ExpectQuery(sqlRe string) *ExpectedQuery {
+	e := &ExpectedQuery{expectedStatement: expectedStatement{re: regexp.MustCompile(sqlRe)}}
+	m.expect(e)
+	return e
+}
+
+// ExpectBatch expects a batch of statements that matches the regular expression 'sqlRe'
+func (m *MockDA) ExpectBatch(sqlRe string) *ExpectedBatch {
+	e := &ExpectedBatch{re: regexp.MustCompile(sqlRe)}
+	m.expect(e)
+	return e
+}
+
+// ExpectBegin expects a transaction to begin
+func (m *MockDA) ExpectBegin() *ExpectedBegin {
+	e := &ExpectedBegin{}
+	m.expect(e)
+	return e
+}
+
+// ExpectCommit expects a transaction to be committed
+func (m *MockDA) ExpectCommit() *ExpectedCommit {
+	e := &ExpectedCommit{}
+	m.expect(e)
+	return e
+}
+
+// ExpectRollback expects a transaction to be rolled back
+func (m *MockDA) ExpectRollback() *ExpectedRollback {
+	e := &ExpectedRollback{}
+	m.expect(e)
+	return e
+}
+
+func (m *MockDA) expect(e expectation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exps = append(m.exps, e)
+}
+
+// match finds the expectation that matches a call, and marks it as triggered
+func (m *MockDA) match(call string, matches func(e expectation) error) (expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.exps {
+		if e.triggered() {
+			continue
+		}
+
+		err := matches(e)
+		if err == nil {
+			e.trigger()
+			return e, nil
+		}
+
+		if m.ordered {
+			return nil, fmt.Errorf("dasqltest: %s was not expected, next expectation is %s: %w",
+				call, e, err)
+		}
+	}
+
+	return nil, fmt.Errorf("dasqltest: %s was not expected", call)
+}
+
+// ExecuteStatementWithContext implements DA
+func (m *MockDA) ExecuteStatementWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.ExecuteStatementInput,
+	opts ...request.Option) (*rdsdataservice.ExecuteStatementOutput, error) {
+	call := fmt.Sprintf("ExecuteStatement with sql '%s'", aws.StringValue(in.Sql))
+	e, err := m.match(call, func(e expectation) error {
+		switch et := e.(type) {
+		case *ExpectedExec:
+			return et.matches(m.conv, in.TransactionId, in.Sql, in.Parameters)
+		case *ExpectedQuery:
+			return et.matches(m.conv, in.TransactionId, in.Sql, in.Parameters)
+		default:
+			return fmt.Errorf("not a statement")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch et := e.(type) {
+	case *ExpectedExec:
+		if et.err != nil {
+			return nil, et.err
+		}
+
+		return et.res.statementOutput(), nil
+	default:
+		et2 := et.(*ExpectedQuery)
+		if et2.err != nil {
+			return nil, et2.err
+		}
+
+		if et2.rows == nil {
+			return &rdsdataservice.ExecuteStatementOutput{}, nil
+		}
+
+		return et2.rows.statementOutput(m.conv, aws.BoolValue(in.IncludeResultMetadata))
+	}
+}
+
+// BeginTransactionWithContext implements DA
+func (m *MockDA) BeginTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.BeginTransactionInput,
+	opts ...request.Option) (*rdsdataservice.BeginTransactionOutput, error) {
+	e, err := m.match("BeginTransaction", func(e expectation) error {
+		if _, ok := e.(*ExpectedBegin); !ok {
+			return fmt.Errorf("not a begin")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if e.(*ExpectedBegin).err != nil {
+		return nil, e.(*ExpectedBegin).err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ntx++
+
+	return (&rdsdataservice.BeginTransactionOutput{}).
+		SetTransactionId("tx" + strconv.Itoa(m.ntx)), nil
+}
+
+// CommitTransactionWithContext implements DA
+func (m *MockDA) CommitTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.CommitTransactionInput,
+	opts ...request.Option) (*rdsdataservice.CommitTransactionOutput, error) {
+	call := fmt.Sprintf("CommitTransaction of '%s'", aws.StringValue(in.TransactionId))
+	e, err := m.match(call, func(e expectation) error {
+		ec, ok := e.(*ExpectedCommit)
+		if !ok {
+			return fmt.Errorf("not a commit")
+		}
+
+		return ec.matchTx(in.TransactionId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if e.(*ExpectedCommit).err != nil {
+		return nil, e.(*ExpectedCommit).err
+	}
+
+	return (&rdsdataservice.CommitTransactionOutput{}).
+		SetTransactionStatus("Transaction Committed"), nil
+}
+
+// RollbackTransactionWithContext implements DA
+func (m *MockDA) RollbackTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.RollbackTransactionInput,
+	opts ...request.Option) (*rdsdataservice.RollbackTransactionOutput, error) {
+	call := fmt.Sprintf("RollbackTransaction of '%s'", aws.StringValue(in.TransactionId))
+	e, err := m.match(call, func(e expectation) error {
+		er, ok := e.(*ExpectedRollback)
+		if !ok {
+			return fmt.Errorf("not a rollback")
+		}
+
+		return er.matchTx(in.TransactionId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if e.(*ExpectedRollback).err != nil {
+		return nil, e.(*ExpectedRollback).err
+	}
+
+	return (&rdsdataservice.RollbackTransactionOutput{}).
+		SetTransactionStatus("Rollback Complete"), nil
+}
+
+// BatchExecuteStatementWithContext implements DA
+func (m *MockDA) BatchExecuteStatementWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.BatchExecuteStatementInput,
+	opts ...request.Option) (*rdsdataservice.BatchExecuteStatementOutput, error) {
+	call := fmt.Sprintf("BatchExecuteStatement with sql '%s'", aws.StringValue(in.Sql))
+	e, err := m.match(call, func(e expectation) error {
+		eb, ok := e.(*ExpectedBatch)
+		if !ok {
+			return fmt.Errorf("not a batch")
+		}
+
+		return eb.matches(m.conv, in.TransactionId, in.Sql, in.ParameterSets)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	eb := e.(*ExpectedBatch)
+	if eb.err != nil {
+		return nil, eb.err
+	}
+
+	out := &rdsdataservice.BatchExecuteStatementOutput{UpdateResults: []*rdsdataservice.UpdateResult{}}
+	for i := range in.ParameterSets {
+		ur := &rdsdataservice.UpdateResult{GeneratedFields: []*rdsdataservice.Field{}}
+		if i < len(eb.res) {
+			ur.GeneratedFields = eb.res[i].statementOutput().GeneratedFields
+		}
+
+		out.UpdateResults = append(out.UpdateResults, ur)
+	}
+
+	return out, nil
+}
+
+// expectation is implemented by all expected calls
+type expectation interface {
+	fmt.Stringer
+	triggered() bool
+	trigger()
+}
+
+// expected holds what is shared by all expectations
+type expected struct {
+	done bool
+	err  error
+	tid  *string // expected transaction id, if any
+}
+
+func (e *expected) triggered() bool { return e.done }
+func (e *expected) trigger()        { e.done = true }
+
+// matchTx returns an error if the transaction id doesn't match the expected one
+func (e *expected) matchTx(tid *string) error {
+	if e.tid == nil || *e.tid == aws.StringValue(tid) {
+		return nil
+	}
+
+	if *e.tid == "" {
+		return fmt.Errorf("expected no transaction, got: '%s'", aws.StringValue(tid))
+	}
+
+	return fmt.Errorf("expected transaction '%s', got: '%s'", *e.tid, aws.StringValue(tid))
+}
+
+// txString describes the expected transaction, if any
+func (e *expected) txString() string {
+	switch {
+	case e.tid == nil:
+		return ""
+	case *e.tid == "":
+		return " outside a transaction"
+	default:
+		return fmt.Sprintf(" in transaction '%s'", *e.tid)
+	}
+}
+
+// expectedStatement is the expectation of a single statement being executed
+type expectedStatement struct {
+	expected
+	re   *regexp.Regexp
+	args []interface{}
+}
+
+// matches returns an error if the transaction, sql or parameters don't match the expectation
+func (e *expectedStatement) matches(
+	conv *dasql.DB, tid, q *string, params []*rdsdataservice.SqlParameter,
+) error {
+	if !e.re.MatchString(aws.StringValue(q)) {
+		return fmt.Errorf("sql '%s' doesn't match '%s'", aws.StringValue(q), e.re)
+	}
+
+	if err := e.matchTx(tid); err != nil {
+		return err
+	}
+
+	if e.args == nil {
+		return nil
+	}
+
+	return matchArgs(conv, e.args, params)
+}
+
+func (e *expectedStatement) String() string {
+	s := fmt.Sprintf("statement matching '%s'", e.re)
+	if e.args != nil {
+		s += fmt.Sprintf(" with args %v", e.args)
+	}
+
+	return s + e.txString()
+}
+
+// ExpectedExec describes an expected statement that doesn't return rows
+type ExpectedExec struct {
+	expectedStatement
+	res Result
+}
+
+// WithArgs sets the arguments the statement is expected to be executed with
+func (e *ExpectedExec) WithArgs(args ...interface{}) *ExpectedExec {
+	e.args = args
+	return e
+}
+
+// WithTx sets the id of the transaction the statement is expected to be executed in, an empty id
+// expects it to be executed outside of a transaction. The mock begins transactions with the ids
+// "tx1", "tx2" and so on.
+func (e *ExpectedExec) WithTx(id string) *ExpectedExec {
+	e.tid = &id
+	return e
+}
+
+// WillReturnResult sets the result that is returned for the statement
+func (e *ExpectedExec) WillReturnResult(res Result) *ExpectedExec {
+	e.res = res
+	return e
+}
+
+// WillReturnError sets the error that is returned when the statement is executed
+func (e *ExpectedExec) WillReturnError(err error) *ExpectedExec {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedExec) String() string { return "exec " + e.expectedStatement.String() }
+
+// ExpectedQuery describes an expected statement that returns rows
+type ExpectedQuery struct {
+	expectedStatement
+	rows *Rows
+}
+
+// WithArgs sets the arguments the query is expected to be executed with
+func (e *ExpectedQuery) WithArgs(args ...interface{}) *ExpectedQuery {
+	e.args = args
+	return e
+}
+
+// WithTx sets the id of the transaction the query is expected to be executed in, an empty id
+// expects it to be executed outside of a transaction.
+func (e *ExpectedQuery) WithTx(id string) *ExpectedQuery {
+	e.tid = &id
+	return e
+}
+
+// WillReturnRows sets the rows that are returned for the query
+func (e *ExpectedQuery) WillReturnRows(rows *Rows) *ExpectedQuery {
+	e.rows = rows
+	return e
+}
+
+// WillReturnError sets the error that is returned when the query is executed
+func (e *ExpectedQuery) WillReturnError(err error) *ExpectedQuery {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedQuery) String() string { return "query " + e.expectedStatement.String() }
+
+// ExpectedBatch describes an expected batch execution
+type ExpectedBatch struct {
+	expected
+	re   *regexp.Regexp
+	sets [][]interface{}
+	res  []Result
+}
+
+// WithArgs adds a set of arguments the batch is expected to be executed with, it can be called
+// multiple times for each set of the batch.
+func (e *ExpectedBatch) WithArgs(args ...interface{}) *ExpectedBatch {
+	e.sets = append(e.sets, args)
+	return e
+}
+
+// WithTx sets the id of the transaction the batch is expected to be executed in, an empty id
+// expects it to be executed outside of a transaction.
+func (e *ExpectedBatch) WithTx(id string) *ExpectedBatch {
+	e.tid = &id
+	return e
+}
+
+// WillReturnResults sets the results that are returned for each set of the batch
+func (e *ExpectedBatch) WillReturnResults(res ...Result) *ExpectedBatch {
+	e.res = res
+	return e
+}
+
+// WillReturnError sets the error that is returned when the batch is executed
+func (e *ExpectedBatch) WillReturnError(err error) *ExpectedBatch {
+	e.err = err
+	return e
+}
+
+// matches returns an error if the transaction, sql or parameter sets don't match the expectation
+func (e *ExpectedBatch) matches(
+	conv *dasql.DB, tid, q *string, sets [][]*rdsdataservice.SqlParameter,
+) error {
+	if !e.re.MatchString(aws.StringValue(q)) {
+		return fmt.Errorf("sql '%s' doesn't match '%s'", aws.StringValue(q), e.re)
+	}
+
+	if err := e.matchTx(tid); err != nil {
+		return err
+	}
+
+	if e.sets == nil {
+		return nil
+	}
+
+	if len(sets) != len(e.sets) {
+		return fmt.Errorf("expected %d parameter sets, got: %d", len(e.sets), len(sets))
+	}
+
+	for i, args := range e.sets {
+		if err := matchArgs(conv, args, sets[i]); err != nil {
+			return fmt.Errorf("parameter set %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (e *ExpectedBatch) String() string {
+	return fmt.Sprintf("batch matching '%s' with %d parameter sets", e.re, len(e.sets)) + e.txString()
+}
+
+// ExpectedBegin describes an expected begin of a transaction
+type ExpectedBegin struct{ expected }
+
+// WillReturnError sets the error that is returned when the transaction begins
+func (e *ExpectedBegin) WillReturnError(err error) *ExpectedBegin {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedBegin) String() string { return "begin transaction" }
+
+// ExpectedCommit describes an expected commit of a transaction
+type ExpectedCommit struct{ expected }
+
+// WithTx sets the id of the transaction that is expected to be committed
+func (e *ExpectedCommit) WithTx(id string) *ExpectedCommit {
+	e.tid = &id
+	return e
+}
+
+// WillReturnError sets the error that is returned when the transaction is committed
+func (e *ExpectedCommit) WillReturnError(err error) *ExpectedCommit {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedCommit) String() string { return "commit transaction" + e.txString() }
+
+// ExpectedRollback describes an expected rollback of a transaction
+type ExpectedRollback struct{ expected }
+
+// WithTx sets the id of the transaction that is expected to be rolled back
+func (e *ExpectedRollback) WithTx(id string) *ExpectedRollback {
+	e.tid = &id
+	return e
+}
+
+// WillReturnError sets the error that is returned when the transaction is rolled back
+func (e *ExpectedRollback) WillReturnError(err error) *ExpectedRollback {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedRollback) String() string { return "rollback transaction" + e.txString() }
+
+// Argument can be used in WithArgs to match parameter values with custom logic
+type Argument interface {
+	Match(f *rdsdataservice.Field) bool
+}
+
+type anyArg struct{}
+
+func (anyArg) Match(*rdsdataservice.Field) bool { return true }
+
+// AnyArg returns an argument that matches any parameter value
+func AnyArg() Argument { return anyArg{} }
+
+// matchArgs returns an error if the parameters don't match the expected (named) arguments
+func matchArgs(conv *dasql.DB, args []interface{}, params []*rdsdataservice.SqlParameter) error {
+	if len(args) != len(params) {
+		return fmt.Errorf("expected %d arguments, got: %d", len(args), len(params))
+	}
+
+	actual := make(map[string]*rdsdataservice.SqlParameter, len(params))
+	for _, p := range params {
+		actual[aws.StringValue(p.Name)] = p
+	}
+
+	for _, arg := range args {
+		named, ok := arg.(sql.NamedArg)
+		if !ok {
+			return fmt.Errorf("expected argument must be a sql.NamedArg, got: %T", arg)
+		}
+
+		p, ok := actual[named.Name]
+		if !ok {
+			return fmt.Errorf("missing argument '%s'", named.Name)
+		}
+
+		if am, ok := named.Value.(Argument); ok {
+			if !am.Match(p.Value) {
+				return fmt.Errorf("argument '%s' doesn't match, got: %s", named.Name, p.Value)
+			}
+
+			continue
+		}
+
+		exp, err := convertValue(conv, named.Value)
+		if err != nil {
+			return err
+		}
+
+		if !reflect.DeepEqual(exp.Value, p.Value) ||
+			aws.StringValue(exp.TypeHint) != aws.StringValue(p.TypeHint) {
+			return fmt.Errorf("argument '%s' doesn't match, expected: %s, got: %s",
+				named.Name, exp, p)
+		}
+	}
+
+	return nil
+}
+
+// convertValue converts a Go value into a parameter with the conversion options of the mock
+func convertValue(conv *dasql.DB, v interface{}) (*rdsdataservice.SqlParameter, error) {
+	ps, err := conv.ConvertArgs(sql.Named("v", v))
+	if err != nil {
+		return nil, err
+	}
+
+	return ps[0], nil
+}
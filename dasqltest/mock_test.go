@@ -0,0 +1,254 @@
+package dasqltest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+	"github.com/go-data-api/dasql"
+)
+
+func TestMockExecQuery(t *testing.T) {
+	m, ctx := New(), context.Background()
+	m.ExpectExec(`^INSERT INTO foo`).
+		WithArgs(sql.Named("bar", "rab"), sql.Named("id", AnyArg())).
+		WillReturnResult(NewResult(42, 1))
+	m.ExpectQuery(`^SELECT`).
+		WithArgs(sql.Named("id", 42)).
+		WillReturnRows(NewRows("bar", "nr").AddRow("rab", 1).AddRow("foo", nil))
+
+	db := dasql.New(m, "arn:aws:rds:", "arn:aws:secret:")
+	res, err := db.Exec(ctx, `INSERT INTO foo (bar, id) VALUES (:bar, :id)`,
+		sql.Named("id", 100), sql.Named("bar", "rab"))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if id, _ := res.LastInsertId(); id != 42 {
+		t.Fatalf("got: %v", id)
+	}
+
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Fatalf("got: %v", n)
+	}
+
+	rows, err := db.Query(ctx, `SELECT bar, nr FROM foo WHERE id = :id`, sql.Named("id", 42))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var bars []string
+	var nrs []sql.NullInt64
+	for rows.Next() {
+		var bar string
+		var nr sql.NullInt64
+		if err = rows.Scan(&bar, &nr); err != nil {
+			t.Fatalf("got: %v", err)
+		}
+
+		bars, nrs = append(bars, bar), append(nrs, nr)
+	}
+
+	if len(bars) != 2 || bars[0] != "rab" || nrs[0].Int64 != 1 || nrs[1].Valid {
+		t.Fatalf("got: %v %v", bars, nrs)
+	}
+
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestMockTxBatch(t *testing.T) {
+	m, ctx := New(), context.Background()
+	m.ExpectBegin()
+	m.ExpectBatch(`^INSERT`).
+		WithArgs(sql.Named("foo", 1)).
+		WithArgs(sql.Named("foo", 2)).
+		WillReturnResults(NewResult(1, 1), NewResult(2, 1))
+	m.ExpectCommit()
+
+	db := dasql.New(m, "arn:aws:rds:", "arn:aws:secret:")
+	tx, err := db.Tx(ctx)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	res, err := tx.ExecBatch(ctx, dasql.NewBatch(`INSERT INTO foo (bar) VALUES (:foo)`).
+		Exec(sql.Named("foo", 1)).
+		Exec(sql.Named("foo", 2)))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if id, _ := res[1].LastInsertId(); len(res) != 2 || id != 2 {
+		t.Fatalf("got: %v", res)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestMockErrors(t *testing.T) {
+	m, ctx := New(), context.Background()
+	exp := &rdsdataservice.BadRequestException{Message_: new(string)}
+	m.ExpectBegin()
+	m.ExpectExec(`^DELETE`).WillReturnError(exp)
+	m.ExpectRollback()
+
+	db := dasql.New(m, "arn:aws:rds:", "arn:aws:secret:")
+	if _, err := db.Exec(ctx, `DELETE FROM foo`); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err := m.ExpectationsWereMet(); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	tx, err := db.Tx(ctx)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM foo`); !errors.Is(err, exp) {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = tx.Rollback(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestMockArgMismatch(t *testing.T) {
+	m, ctx := New(), context.Background()
+	m.ExpectExec(`^DELETE`).WithArgs(sql.Named("id", 1))
+
+	db := dasql.New(m, "arn:aws:rds:", "arn:aws:secret:")
+	for _, args := range [][]interface{}{
+		{sql.Named("id", 2)},
+		{sql.Named("id", "1")},
+		{sql.Named("di", 1)},
+		{},
+	} {
+		if _, err := db.Exec(ctx, `DELETE FROM foo WHERE id = :id`, args...); err == nil {
+			t.Fatalf("got: %v", err)
+		}
+	}
+
+	if _, err := db.Exec(ctx, `DELETE FROM foo WHERE id = :id`, sql.Named("id", 1)); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestMockUnordered(t *testing.T) {
+	m, ctx := New(), context.Background()
+	m.MatchExpectationsInOrder(false)
+	m.ExpectExec(`^UPDATE`)
+	m.ExpectExec(`^DELETE`)
+
+	db := dasql.New(m, "arn:aws:rds:", "arn:aws:secret:")
+	for _, q := range []string{`DELETE FROM foo`, `UPDATE foo SET bar = 1`} {
+		if _, err := db.Exec(ctx, q); err != nil {
+			t.Fatalf("got: %v", err)
+		}
+	}
+
+	if _, err := db.Exec(ctx, `DELETE FROM foo`); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	m = New()
+	m.ExpectExec(`^UPDATE`)
+	m.ExpectExec(`^DELETE`)
+	db = dasql.New(m, "arn:aws:rds:", "arn:aws:secret:")
+	if _, err := db.Exec(ctx, `DELETE FROM foo`); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestMockWithTx(t *testing.T) {
+	m, ctx := New(), context.Background()
+	m.ExpectBegin()
+	m.ExpectExec(`^DELETE`).WithTx("tx1")
+	m.ExpectCommit().WithTx("tx1")
+
+	db := dasql.New(m, "arn:aws:rds:", "arn:aws:secret:")
+	tx, err := db.Tx(ctx)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	_, err = db.Exec(ctx, `DELETE FROM foo`)
+	if err == nil || !strings.Contains(err.Error(), "expected transaction 'tx1'") {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM foo`); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	m.ExpectQuery(`^SELECT`).WithTx("")
+	m.ExpectRollback().WithTx("tx2")
+	m.ExpectBegin()
+	if _, err = db.Query(ctx, `SELECT 1`); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = m.ExpectationsWereMet(); err == nil || !strings.Contains(err.Error(), "in transaction 'tx2'") {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestMockRowsConversion(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "2 values, expected 1") {
+			t.Fatalf("got: %v", r)
+		}
+	}()
+
+	loc := time.FixedZone("X", 3600)
+	m, ctx := New(dasql.WithLocation(loc)), context.Background()
+	m.ExpectQuery(`^SELECT`).
+		WithArgs(sql.Named("t", time.Date(2020, 1, 1, 12, 0, 0, 0, loc))).
+		WillReturnRows(NewRows("t").AddRow(time.Date(2020, 1, 1, 12, 0, 0, 0, loc)))
+
+	db := dasql.New(m, "arn:aws:rds:", "arn:aws:secret:", dasql.WithLocation(loc))
+	rows, err := db.Query(ctx, `SELECT t FROM foo WHERE t = :t`,
+		sql.Named("t", time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var act time.Time
+	for rows.Next() {
+		if err = rows.Scan(&act); err != nil {
+			t.Fatalf("got: %v", err)
+		}
+	}
+
+	if act.Hour() != 12 || act.Location() != loc {
+		t.Fatalf("got: %v", act)
+	}
+
+	NewRows("a").AddRow(1, 2)
+}
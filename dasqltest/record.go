@@ -0,0 +1,362 @@
+package dasqltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+	"github.com/go-data-api/dasql"
+)
+
+var (
+	_ dasql.DA = &Recorder{}
+	_ dasql.DA = &Replayer{}
+)
+
+// recording is a single call to the Data API as it is stored in a recording file
+type recording struct {
+	Op     string          `json:"op"`
+	Input  json.RawMessage `json:"input"`
+	Output json.RawMessage `json:"output,omitempty"`
+	Error  *recordedErr    `json:"error,omitempty"`
+}
+
+// recordedErr is an error as it is stored in a recording file
+type recordedErr struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// Recorder implements the DA interface by calling another DA and recording every call, with its
+// output or error, so it can be saved to a file and replayed later with a Replayer. Calls always
+// return the result of the other DA, failures to record them are returned by Save.
+type Recorder struct {
+	da   dasql.DA
+	path string
+	mu   sync.Mutex
+	recs []recording
+	err  error // first failure to record a call
+}
+
+// Record creates a recorder that calls 'da' and saves the recording to 'path' when Save is called
+func Record(da dasql.DA, path string) *Recorder { return &Recorder{da: da, path: path} }
+
+// Save writes all calls that were recorded so far to the file. It fails without writing if any
+// of the calls could not be recorded, since the recording would be incomplete.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	recs := r.recs
+	if recs == nil {
+		recs = []recording{}
+	}
+
+	data, err := json.MarshalIndent(recs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("dasqltest: failed to encode recording: %w", err)
+	}
+
+	if err = ioutil.WriteFile(r.path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("dasqltest: failed to write recording: %w", err)
+	}
+
+	return nil
+}
+
+// record adds a call with its input, output and error to the recording, a failure to do so is
+// remembered so it can be returned by Save.
+func (r *Recorder) record(op string, in, out interface{}, err error) {
+	rec, rerr := newRecording(op, in, out, err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rerr != nil {
+		if r.err == nil {
+			r.err = fmt.Errorf("dasqltest: failed to record %s: %w", op, rerr)
+		}
+
+		return
+	}
+
+	r.recs = append(r.recs, rec)
+}
+
+// newRecording encodes a call with its input, output and error
+func newRecording(op string, in, out interface{}, err error) (rec recording, merr error) {
+	rec.Op = op
+	if rec.Input, merr = json.Marshal(in); merr != nil {
+		return rec, fmt.Errorf("failed to encode input: %w", merr)
+	}
+
+	if err != nil {
+		rec.Error = &recordedErr{Message: err.Error()}
+
+		var aerr awserr.Error
+		if errors.As(err, &aerr) {
+			rec.Error.Code, rec.Error.Message = aerr.Code(), aerr.Message()
+		}
+	} else if rec.Output, merr = json.Marshal(out); merr != nil {
+		return rec, fmt.Errorf("failed to encode output: %w", merr)
+	}
+
+	return rec, nil
+}
+
+// ExecuteStatementWithContext implements DA
+func (r *Recorder) ExecuteStatementWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.ExecuteStatementInput,
+	opts ...request.Option) (*rdsdataservice.ExecuteStatementOutput, error) {
+	out, err := r.da.ExecuteStatementWithContext(ctx, in, opts...)
+	r.record("ExecuteStatement", in, out, err)
+	return out, err
+}
+
+// BeginTransactionWithContext implements DA
+func (r *Recorder) BeginTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.BeginTransactionInput,
+	opts ...request.Option) (*rdsdataservice.BeginTransactionOutput, error) {
+	out, err := r.da.BeginTransactionWithContext(ctx, in, opts...)
+	r.record("BeginTransaction", in, out, err)
+	return out, err
+}
+
+// CommitTransactionWithContext implements DA
+func (r *Recorder) CommitTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.CommitTransactionInput,
+	opts ...request.Option) (*rdsdataservice.CommitTransactionOutput, error) {
+	out, err := r.da.CommitTransactionWithContext(ctx, in, opts...)
+	r.record("CommitTransaction", in, out, err)
+	return out, err
+}
+
+// RollbackTransactionWithContext implements DA
+func (r *Recorder) RollbackTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.RollbackTransactionInput,
+	opts ...request.Option) (*rdsdataservice.RollbackTransactionOutput, error) {
+	out, err := r.da.RollbackTransactionWithContext(ctx, in, opts...)
+	r.record("RollbackTransaction", in, out, err)
+	return out, err
+}
+
+// BatchExecuteStatementWithContext implements DA
+func (r *Recorder) BatchExecuteStatementWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.BatchExecuteStatementInput,
+	opts ...request.Option) (*rdsdataservice.BatchExecuteStatementOutput, error) {
+	out, err := r.da.BatchExecuteStatementWithContext(ctx, in, opts...)
+	r.record("BatchExecuteStatement", in, out, err)
+	return out, err
+}
+
+// Replayer implements the DA interface by serving the calls from a recording. Each call must
+// match the next recorded call on operation, transaction, SQL, parameters and result set options.
+type Replayer struct {
+	mu   sync.Mutex
+	recs []recording
+	pos  int
+}
+
+// Replay reads the recording at 'path' and creates a replayer for it
+func Replay(path string) (*Replayer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("dasqltest: failed to read recording: %w", err)
+	}
+
+	r := &Replayer{}
+	if err = json.Unmarshal(data, &r.recs); err != nil {
+		return nil, fmt.Errorf("dasqltest: failed to decode recording: %w", err)
+	}
+
+	return r, nil
+}
+
+// ExpectationsWereMet returns an error if not all recorded calls have been replayed
+func (r *Replayer) ExpectationsWereMet() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n := len(r.recs) - r.pos; n > 0 {
+		return fmt.Errorf("dasqltest: %d recorded call(s) were not replayed, next is %s",
+			n, r.recs[r.pos].Op)
+	}
+
+	return nil
+}
+
+// replay matches the call against the next recording and decodes its output into 'out'
+func (r *Replayer) replay(op string, in, out interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pos >= len(r.recs) {
+		return fmt.Errorf("dasqltest: %s was not recorded, all calls have been replayed", op)
+	}
+
+	rec := r.recs[r.pos]
+	if err := matchRecording(rec, op, in); err != nil {
+		return fmt.Errorf("dasqltest: %s doesn't match call %d of the recording: %w", op, r.pos, err)
+	}
+
+	r.pos++
+	if rec.Error != nil {
+		return rec.Error.err()
+	}
+
+	if err := json.Unmarshal(rec.Output, out); err != nil {
+		return fmt.Errorf("dasqltest: failed to decode output: %w", err)
+	}
+
+	return nil
+}
+
+// recordedStatement holds the parts of a recorded input that are matched on replay
+type recordedStatement struct {
+	Sql              *string
+	TransactionId    *string
+	Parameters       json.RawMessage
+	ParameterSets    json.RawMessage
+	ResultSetOptions json.RawMessage
+}
+
+// matchRecording returns an error if the recording doesn't match the operation and its input
+func matchRecording(rec recording, op string, in interface{}) error {
+	if rec.Op != op {
+		return fmt.Errorf("recorded operation is %s", rec.Op)
+	}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	var exp, act recordedStatement
+	if err = json.Unmarshal(rec.Input, &exp); err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(data, &act); err != nil {
+		return err
+	}
+
+	if aws.StringValue(exp.Sql) != aws.StringValue(act.Sql) {
+		return fmt.Errorf("recorded sql is '%s'", aws.StringValue(exp.Sql))
+	}
+
+	if aws.StringValue(exp.TransactionId) != aws.StringValue(act.TransactionId) {
+		return fmt.Errorf("recorded transaction is '%s'", aws.StringValue(exp.TransactionId))
+	}
+
+	if !jsonEqual(exp.Parameters, act.Parameters) || !jsonEqual(exp.ParameterSets, act.ParameterSets) {
+		return errors.New("parameters differ from the recording")
+	}
+
+	if !jsonEqual(exp.ResultSetOptions, act.ResultSetOptions) {
+		return errors.New("result set options differ from the recording")
+	}
+
+	return nil
+}
+
+// jsonEqual returns whether two encoded json values are equal, ignoring insignificant whitespace
+func jsonEqual(a, b json.RawMessage) bool {
+	var ab, bb bytes.Buffer
+	if len(a) > 0 {
+		if err := json.Compact(&ab, a); err != nil {
+			return false
+		}
+	}
+
+	if len(b) > 0 {
+		if err := json.Compact(&bb, b); err != nil {
+			return false
+		}
+	}
+
+	return bytes.Equal(ab.Bytes(), bb.Bytes())
+}
+
+// err rebuilds the error that was recorded, the exceptions of the Data API are returned as their
+// typed counterparts so they can be asserted on just like the real thing.
+func (e *recordedErr) err() error {
+	msg := aws.String(e.Message)
+	switch e.Code {
+	case "":
+		return errors.New(e.Message)
+	case rdsdataservice.ErrCodeBadRequestException:
+		return &rdsdataservice.BadRequestException{Message_: msg}
+	case rdsdataservice.ErrCodeForbiddenException:
+		return &rdsdataservice.ForbiddenException{Message_: msg}
+	case rdsdataservice.ErrCodeNotFoundException:
+		return &rdsdataservice.NotFoundException{Message_: msg}
+	case rdsdataservice.ErrCodeInternalServerErrorException:
+		return &rdsdataservice.InternalServerErrorException{}
+	case rdsdataservice.ErrCodeServiceUnavailableError:
+		return &rdsdataservice.ServiceUnavailableError{}
+	case rdsdataservice.ErrCodeStatementTimeoutException:
+		return &rdsdataservice.StatementTimeoutException{Message_: msg}
+	default:
+		return awserr.New(e.Code, e.Message, nil)
+	}
+}
+
+// ExecuteStatementWithContext implements DA
+func (r *Replayer) ExecuteStatementWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.ExecuteStatementInput,
+	opts ...request.Option) (out *rdsdataservice.ExecuteStatementOutput, err error) {
+	err = r.replay("ExecuteStatement", in, &out)
+	return out, err
+}
+
+// BeginTransactionWithContext implements DA
+func (r *Replayer) BeginTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.BeginTransactionInput,
+	opts ...request.Option) (out *rdsdataservice.BeginTransactionOutput, err error) {
+	err = r.replay("BeginTransaction", in, &out)
+	return out, err
+}
+
+// CommitTransactionWithContext implements DA
+func (r *Replayer) CommitTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.CommitTransactionInput,
+	opts ...request.Option) (out *rdsdataservice.CommitTransactionOutput, err error) {
+	err = r.replay("CommitTransaction", in, &out)
+	return out, err
+}
+
+// RollbackTransactionWithContext implements DA
+func (r *Replayer) RollbackTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.RollbackTransactionInput,
+	opts ...request.Option) (out *rdsdataservice.RollbackTransactionOutput, err error) {
+	err = r.replay("RollbackTransaction", in, &out)
+	return out, err
+}
+
+// BatchExecuteStatementWithContext implements DA
+func (r *Replayer) BatchExecuteStatementWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.BatchExecuteStatementInput,
+	opts ...request.Option) (out *rdsdataservice.BatchExecuteStatementOutput, err error) {
+	err = r.replay("BatchExecuteStatement", in, &out)
+	return out, err
+}
@@ -0,0 +1,268 @@
+package dasqltest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+	"github.com/go-data-api/dasql"
+)
+
+// run executes the same calls against a DB for recording and replaying
+func run(ctx context.Context, db *dasql.DB) (string, error, error) {
+	tx, err := db.Tx(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rows, err := tx.Query(ctx, `SELECT bar FROM foo WHERE id = :id`, sql.Named("id", 1))
+	if err != nil {
+		return "", nil, err
+	}
+
+	var bar string
+	for rows.Next() {
+		if err = rows.Scan(&bar); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if _, err = tx.ExecBatch(ctx, dasql.NewBatch(`INSERT INTO foo (bar) VALUES (:bar)`).
+		Exec(sql.Named("bar", "a")).
+		Exec(sql.Named("bar", "b"))); err != nil {
+		return "", nil, err
+	}
+
+	_, xerr := tx.Exec(ctx, `DELETE FROM foo`)
+	return bar, xerr, tx.Rollback()
+}
+
+func TestRecordReplay(t *testing.T) {
+	m, ctx := New(), context.Background()
+	m.ExpectBegin()
+	m.ExpectQuery(`^SELECT`).WillReturnRows(NewRows("bar").AddRow("rab"))
+	m.ExpectBatch(`^INSERT`).WillReturnResults(NewResult(1, 1), NewResult(2, 1))
+	m.ExpectExec(`^DELETE`).WillReturnError(
+		&rdsdataservice.BadRequestException{Message_: aws.String("no delete")})
+	m.ExpectRollback()
+
+	path := filepath.Join(t.TempDir(), "foo.json")
+	rec := Record(m, path)
+	bar, xerr, err := run(ctx, dasql.New(rec, "arn:aws:rds:", "arn:aws:secret:"))
+	if err != nil || bar != "rab" || xerr == nil {
+		t.Fatalf("got: %v %v %v", bar, xerr, err)
+	}
+
+	if err = rec.Save(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	rep, err := Replay(path)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	bar, xerr, err = run(ctx, dasql.New(rep, "arn:aws:rds:", "arn:aws:secret:"))
+	if err != nil || bar != "rab" {
+		t.Fatalf("got: %v %v", bar, err)
+	}
+
+	var bre *rdsdataservice.BadRequestException
+	if !errors.As(xerr, &bre) || bre.Message() != "no delete" {
+		t.Fatalf("got: %v", xerr)
+	}
+
+	if err = rep.ExpectationsWereMet(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+// cursor reads the ids through a cursor, for recording and replaying
+func cursor(ctx context.Context, db *dasql.DB) ([]int64, error) {
+	tx, err := db.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+	rows, err := dasql.QueryCursor(ctx, tx, `SELECT id FROM foo`, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err = rows.Close(); err != nil {
+		return nil, err
+	}
+
+	return ids, tx.Commit()
+}
+
+func TestRecordReplayCursor(t *testing.T) {
+	m, ctx := New(dasql.WithDialect(dasql.DialectPostgres)), context.Background()
+	m.ExpectBegin()
+	m.ExpectExec(`^DECLARE`)
+	m.ExpectQuery(`^FETCH`).WillReturnRows(NewRows("id").AddRow(1).AddRow(2))
+	m.ExpectExec(`^CLOSE`)
+	m.ExpectCommit()
+
+	path := filepath.Join(t.TempDir(), "foo.json")
+	rec := Record(m, path)
+	ids, err := cursor(ctx, dasql.New(rec, "arn:aws:rds:", "arn:aws:secret:",
+		dasql.WithDialect(dasql.DialectPostgres)))
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("got: %v %v", ids, err)
+	}
+
+	if err = rec.Save(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	rep, err := Replay(path)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	ids, err = cursor(ctx, dasql.New(rep, "arn:aws:rds:", "arn:aws:secret:",
+		dasql.WithDialect(dasql.DialectPostgres)))
+	if err != nil || len(ids) != 2 || ids[1] != 2 {
+		t.Fatalf("got: %v %v", ids, err)
+	}
+
+	if err = rep.ExpectationsWereMet(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	m, ctx := New(), context.Background()
+	m.ExpectExec(`^DELETE`)
+	m.ExpectExec(`^UPDATE`)
+
+	path := filepath.Join(t.TempDir(), "foo.json")
+	rec := Record(m, path)
+	db := dasql.New(rec, "arn:aws:rds:", "arn:aws:secret:")
+	if _, err := db.Exec(ctx, `DELETE FROM foo WHERE id = :id`, sql.Named("id", 1)); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err := db.Exec(ctx, `UPDATE foo SET bar = 1`); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err := rec.Save(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	for i, c := range []struct {
+		q    string
+		args []interface{}
+	}{
+		{`UPDATE foo SET bar = 1`, nil},
+		{`DELETE FROM foo WHERE id = :id`, []interface{}{sql.Named("id", 2)}},
+		{`DELETE FROM foo WHERE id = :id`, []interface{}{sql.Named("id", "1")}},
+	} {
+		rep, err := Replay(path)
+		if err != nil {
+			t.Fatalf("got: %v", err)
+		}
+
+		db = dasql.New(rep, "arn:aws:rds:", "arn:aws:secret:")
+		if _, err = db.Exec(ctx, c.q, c.args...); err == nil {
+			t.Fatalf("%d: got: %v", i, err)
+		}
+	}
+
+	rep, _ := Replay(path)
+	db = dasql.New(rep, "arn:aws:rds:", "arn:aws:secret:")
+	if _, err := db.Exec(ctx, `DELETE FROM foo WHERE id = :id`, sql.Named("id", 1)); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err := rep.ExpectationsWereMet(); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err := Replay(filepath.Join(t.TempDir(), "bogus.json")); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestReplayTxMismatch(t *testing.T) {
+	m, ctx := New(), context.Background()
+	m.ExpectBegin()
+	m.ExpectCommit()
+
+	path := filepath.Join(t.TempDir(), "foo.json")
+	rec := Record(m, path)
+	tx, err := dasql.New(rec, "arn:aws:rds:", "arn:aws:secret:").Tx(ctx)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = rec.Save(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	rep, err := Replay(path)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err = rep.BeginTransactionWithContext(ctx,
+		&rdsdataservice.BeginTransactionInput{}); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	_, err = rep.CommitTransactionWithContext(ctx, (&rdsdataservice.CommitTransactionInput{}).
+		SetTransactionId("tx2"))
+	if err == nil || !strings.Contains(err.Error(), "recorded transaction is 'tx1'") {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestRecordFailure(t *testing.T) {
+	m, ctx := New(), context.Background()
+	m.ExpectQuery(`^SELECT`).WillReturnRows(NewRows("nan").AddRow(math.NaN()))
+
+	rec := Record(m, filepath.Join(t.TempDir(), "foo.json"))
+	rows, err := dasql.New(rec, "arn:aws:rds:", "arn:aws:secret:").Query(ctx, `SELECT 'NaN'`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var f float64
+	for rows.Next() {
+		if err = rows.Scan(&f); err != nil {
+			t.Fatalf("got: %v", err)
+		}
+	}
+
+	if !math.IsNaN(f) {
+		t.Fatalf("got: %v", f)
+	}
+
+	err = rec.Save()
+	if err == nil || !strings.Contains(err.Error(), "failed to record ExecuteStatement") {
+		t.Fatalf("got: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package dasqltest
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+	"github.com/go-data-api/dasql"
+)
+
+// Result describes the result of an exec statement
+type Result struct {
+	LastInsertID int64
+	RowsAffected int64
+}
+
+// NewResult creates a result with the id that was generated and nr of rows that were affected
+func NewResult(lastInsertID, rowsAffected int64) Result {
+	return Result{lastInsertID, rowsAffected}
+}
+
+// statementOutput turns the result into the output of the Data API
+func (r Result) statementOutput() *rdsdataservice.ExecuteStatementOutput {
+	out := &rdsdataservice.ExecuteStatementOutput{
+		GeneratedFields:        []*rdsdataservice.Field{},
+		NumberOfRecordsUpdated: aws.Int64(r.RowsAffected),
+	}
+
+	if r.LastInsertID != 0 {
+		out.GeneratedFields = append(out.GeneratedFields,
+			&rdsdataservice.Field{LongValue: aws.Int64(r.LastInsertID)})
+	}
+
+	return out
+}
+
+// Rows describes the records that are returned by a query
+type Rows struct {
+	cols []string
+	rows [][]interface{}
+}
+
+// NewRows creates rows with the provided column names
+func NewRows(columns ...string) *Rows { return &Rows{cols: columns} }
+
+// AddRow adds a row of Go values, they are converted into fields with the same rules that are
+// used to convert arguments. So a value that can be passed as an argument can be scanned back. It
+// panics if the nr of values doesn't match the nr of columns.
+func (r *Rows) AddRow(values ...interface{}) *Rows {
+	if len(values) != len(r.cols) {
+		panic(fmt.Sprintf("dasqltest: row has %d values, expected %d for columns %v",
+			len(values), len(r.cols), r.cols))
+	}
+
+	r.rows = append(r.rows, values)
+	return r
+}
+
+// statementOutput turns the rows into the output of the Data API
+func (r *Rows) statementOutput(
+	conv *dasql.DB, meta bool,
+) (*rdsdataservice.ExecuteStatementOutput, error) {
+	out := &rdsdataservice.ExecuteStatementOutput{
+		NumberOfRecordsUpdated: aws.Int64(0),
+		Records:                [][]*rdsdataservice.Field{},
+	}
+
+	if meta {
+		for _, c := range r.cols {
+			out.ColumnMetadata = append(out.ColumnMetadata,
+				(&rdsdataservice.ColumnMetadata{}).SetName(c).SetLabel(c))
+		}
+	}
+
+	for _, row := range r.rows {
+		rec := make([]*rdsdataservice.Field, len(row))
+		for i, v := range row {
+			p, err := convertValue(conv, v)
+			if err != nil {
+				return nil, err
+			}
+
+			rec[i] = p.Value
+		}
+
+		out.Records = append(out.Records, rec)
+	}
+
+	return out, nil
+}
@@ -2,7 +2,10 @@ package dasql
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/rdsdataservice"
@@ -12,13 +15,89 @@ import (
 type DB struct {
 	secretARN   string
 	resourceARN string
+	database    string
+	schema      string
+	dialect     Dialect
+	mapper      *structMapper
+	codec       *codec
+	rsOptions   *rdsdataservice.ResultSetOptions
+	maxList     int
+	txKey       []byte
 
 	da DA
 }
 
+// Option configures the DB
+type Option func(db *DB)
+
+// WithDatabase configures the name of the database that statements and transactions use
+func WithDatabase(name string) Option { return func(db *DB) { db.database = name } }
+
+// WithSchema configures the name of the schema that statements and transactions use
+func WithSchema(name string) Option { return func(db *DB) { db.schema = name } }
+
+// WithDialect configures the SQL dialect of the queries. Positional placeholders of that dialect
+// ('?' for MySQL, '$1' for Postgres) are rewritten into named parameters and are matched with the
+// positional arguments, so queries can be shared with drivers that don't support named ones.
+func WithDialect(d Dialect) Option { return func(db *DB) { db.dialect = d } }
+
+// WithNameMapper configures how struct fields without a 'db' tag are mapped to column names by
+// ScanStruct and ScanAll. By default the field name is lower cased.
+func WithNameMapper(fn NameMapper) Option {
+	return func(db *DB) { db.mapper = newStructMapper(fn) }
+}
+
+// WithLocation configures the location of date and time values. Time arguments are converted to
+// this location and scanned values without a zone are parsed in it. The default is UTC, which is
+// also used when 'loc' is nil.
+func WithLocation(loc *time.Location) Option {
+	return func(db *DB) {
+		if loc == nil {
+			loc = time.UTC
+		}
+
+		db.codec.loc = loc
+	}
+}
+
+// WithNullPolicy configures what happens when a NULL field is scanned into a destination that is
+// not a sql.Scanner (such as sql.NullString). The default is NullAsNil.
+func WithNullPolicy(p NullPolicy) Option { return func(db *DB) { db.codec.nulls = p } }
+
+// WithTypes configures the registry with custom conversions for arguments and scan destinations.
+// An adapted database (StdDB) doesn't use it, its driver converts with driver.Valuer and
+// sql.Scanner instead. The driver of this package (see NewConnector) only uses the encoders, since
+// database/sql converts the scanned values itself. Pass the option to dasqltest.New to match
+// arguments with the same conversions in a mock.
+func WithTypes(r *TypeRegistry) Option { return func(db *DB) { db.codec.types = r } }
+
+// WithMaxListSize limits the number of elements of slice arguments, which are expanded into a list
+// of parameters: 'id IN (:ids)' becomes 'id IN (:ids_0, :ids_1)'. There is no limit by default.
+func WithMaxListSize(n int) Option { return func(db *DB) { db.maxList = n } }
+
+// WithTxTokenKey configures the key that signs and verifies transaction tokens with HMAC-SHA256,
+// see TxToken and ResumeTxToken.
+func WithTxTokenKey(key []byte) Option { return func(db *DB) { db.txKey = key } }
+
+// WithDecimalReturnType configures how the Data API returns DECIMAL values of query results:
+// rdsdataservice.DecimalReturnTypeString (the default) returns them as strings without losing
+// precision, DecimalReturnTypeDoubleOrLong returns them as numbers. It can be overwritten per query
+// by passing a *rdsdataservice.ResultSetOptions as one of the arguments, which is ignored by
+// batches. Statements fail before they are sent if the type is not one of
+// rdsdataservice.DecimalReturnType_Values.
+func WithDecimalReturnType(t string) Option {
+	return func(db *DB) { db.rsOptions = (&rdsdataservice.ResultSetOptions{}).SetDecimalReturnType(t) }
+}
+
 // New initializes the database abstraction
-func New(da DA, resourceARN, secretARN string) *DB {
-	return &DB{secretARN, resourceARN, da}
+func New(da DA, resourceARN, secretARN string, opts ...Option) *DB {
+	c := *defaultCodec
+	db := &DB{secretARN: secretARN, resourceARN: resourceARN, mapper: defaultMapper, codec: &c, da: da}
+	for _, o := range opts {
+		o(db)
+	}
+
+	return db
 }
 
 // Tx begins a transaction. The provided context will be used for the duration of that transaction.
@@ -27,12 +106,32 @@ func (db *DB) Tx(ctx context.Context) (Tx, error) {
 		SetResourceArn(db.resourceARN).
 		SetSecretArn(db.secretARN)
 
+	if db.database != "" {
+		in.SetDatabase(db.database)
+	}
+
+	if db.schema != "" {
+		in.SetSchema(db.schema)
+	}
+
 	out, err := db.da.BeginTransactionWithContext(ctx, in)
 	if err != nil {
 		return nil, fmt.Errorf("dasql: failed to begin transaction: %w", err)
 	}
 
-	return &daTx{aws.StringValue(out.TransactionId), db, ctx}, nil
+	return newTx(ctx, aws.StringValue(out.TransactionId), db), nil
+}
+
+// ResumeTx returns the transaction with id 'id' that was started elsewhere, for example by another
+// process, so it can be continued, committed or rolled back. Like with Tx, the transaction is
+// rolled back when 'ctx' is done before it is committed. Use DetachTx when the transaction should
+// outlive 'ctx', for example when it is handed on to yet another process.
+func (db *DB) ResumeTx(ctx context.Context, id string) (Tx, error) {
+	if id == "" {
+		return nil, errors.New("dasql: transaction id is empty")
+	}
+
+	return newTx(ctx, id, db), nil
 }
 
 // Query queries SQL.The args are for any named parameters in the query.
@@ -42,12 +141,12 @@ func (db *DB) Query(ctx context.Context, q string, args ...interface{}) (Rows, e
 
 // qury is the private implementation that also works with a transaction
 func (db *DB) query(ctx context.Context, tid string, q string, args ...interface{}) (Rows, error) {
-	out, err := db.execStatement(ctx, tid, q, args...)
+	out, err := db.execStatement(ctx, tid, true, q, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &daRows{out.Records, -1}, nil
+	return &daRows{out.Records, -1, out.ColumnMetadata, db.mapper, db.codec, 0}, nil
 }
 
 // Exec executes SQL.The args are for any named parameters in the query.
@@ -57,7 +156,7 @@ func (db *DB) Exec(ctx context.Context, q string, args ...interface{}) (Result,
 
 // exec is the private implementation that also works with a transaction
 func (db *DB) exec(ctx context.Context, tid string, q string, args ...interface{}) (Result, error) {
-	out, err := db.execStatement(ctx, tid, q, args...)
+	out, err := db.execStatement(ctx, tid, false, q, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -68,14 +167,25 @@ func (db *DB) exec(ctx context.Context, tid string, q string, args ...interface{
 	}, nil
 }
 
-// execStatement calls the actual data api for executing both query and exec.
+// execStatement calls the actual data api for executing both query and exec. The column metadata
+// is only requested if 'meta' is true.
 func (db *DB) execStatement(
 	ctx context.Context,
 	tid string,
+	meta bool,
 	q string,
 	args ...interface{},
 ) (*rdsdataservice.ExecuteStatementOutput, error) {
-	params, err := ConvertArgs(args...)
+	args, rso := resultSetOptions(args)
+	if rso == nil {
+		rso = db.rsOptions
+	}
+
+	if err := checkResultSetOptions(rso); err != nil {
+		return nil, err
+	}
+
+	q, params, err := db.prepare(q, args, true)
 	if err != nil {
 		return nil, fmt.Errorf("dasql: failed to convert arguments: %w", err)
 	}
@@ -86,10 +196,26 @@ func (db *DB) execStatement(
 		SetSql(q).
 		SetParameters(params)
 
+	if db.database != "" {
+		in.SetDatabase(db.database)
+	}
+
+	if db.schema != "" {
+		in.SetSchema(db.schema)
+	}
+
 	if tid != "" {
 		in.SetTransactionId(tid)
 	}
 
+	if meta {
+		in.SetIncludeResultMetadata(true)
+	}
+
+	if rso != nil {
+		in.SetResultSetOptions(rso)
+	}
+
 	out, err := db.da.ExecuteStatementWithContext(ctx, in)
 	if err != nil {
 		return nil, fmt.Errorf("dasql: failed to execute statement: %w", err)
@@ -98,6 +224,75 @@ func (db *DB) execStatement(
 	return out, nil
 }
 
+// resultSetOptions removes the result set options from the args, and returns them separately
+func resultSetOptions(args []interface{}) ([]interface{}, *rdsdataservice.ResultSetOptions) {
+	var rso *rdsdataservice.ResultSetOptions
+	nargs := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		if o, ok := arg.(*rdsdataservice.ResultSetOptions); ok {
+			rso = o
+			continue
+		}
+
+		nargs = append(nargs, arg)
+	}
+
+	return nargs, rso
+}
+
+// checkResultSetOptions returns an error if the options hold a decimal return type that the Data
+// API doesn't know, instead of leaving it to a BadRequestException
+func checkResultSetOptions(rso *rdsdataservice.ResultSetOptions) error {
+	if rso == nil || rso.DecimalReturnType == nil {
+		return nil
+	}
+
+	vals := rdsdataservice.DecimalReturnType_Values()
+	for _, v := range vals {
+		if *rso.DecimalReturnType == v {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("dasql: invalid decimal return type '%s', expected one of: %s",
+		*rso.DecimalReturnType, strings.Join(vals, ", "))
+}
+
+// prepare turns the query and its arguments into the sql and parameters for the Data API. Slice
+// arguments are expanded into lists if 'lists' is true, which is not possible for a batch since
+// every parameter set has to use the same sql.
+func (db *DB) prepare(
+	q string, args []interface{}, lists bool,
+) (string, []*rdsdataservice.SqlParameter, error) {
+	args, err := expandNamed(q, db.dialect, db.mapper, args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	q, args, err = rewritePositional(q, db.dialect, args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if lists {
+		q, args, err = expandLists(q, db.dialect, db.maxList, db.codec.types, args)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	params, err := db.codec.convertArgs(args...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err = validateParams(q, db.dialect, params); err != nil {
+		return "", nil, err
+	}
+
+	return q, params, nil
+}
+
 // ExecBatch will execute the batch.
 func (db *DB) ExecBatch(ctx context.Context, b *Batch) ([]Result, error) {
 	return db.execBatch(ctx, "", b)
@@ -105,9 +300,11 @@ func (db *DB) ExecBatch(ctx context.Context, b *Batch) ([]Result, error) {
 
 // execBatch is the private implementation for batching with support for doing it as part of a tx.
 func (db *DB) execBatch(ctx context.Context, tid string, b *Batch) (res []Result, err error) {
+	q := b.sql
 	params := make([][]*rdsdataservice.SqlParameter, len(b.qrys)+len(b.exes))
 	for i, bp := range append(b.qrys, b.exes...) {
-		params[i], err = ConvertArgs(bp...)
+		bp, _ = resultSetOptions(bp) // a batch returns no result sets
+		q, params[i], err = db.prepare(b.sql, bp, false)
 		if err != nil {
 			return nil, err
 		}
@@ -116,9 +313,17 @@ func (db *DB) execBatch(ctx context.Context, tid string, b *Batch) (res []Result
 	in := (&rdsdataservice.BatchExecuteStatementInput{}).
 		SetResourceArn(db.resourceARN).
 		SetSecretArn(db.secretARN).
-		SetSql(b.sql).
+		SetSql(q).
 		SetParameterSets(params)
 
+	if db.database != "" {
+		in.SetDatabase(db.database)
+	}
+
+	if db.schema != "" {
+		in.SetSchema(db.schema)
+	}
+
 	if tid != "" {
 		in.SetTransactionId(tid)
 	}
@@ -58,7 +58,7 @@ func TestDBQueryArgErr(t *testing.T) {
 func TestDBQueryArgAwsErr(t *testing.T) {
 	da := &stubDA{nextESOE: awserr.New("400", "foo", nil)}
 
-	_, err := New(da, "", "").Query(nil, ``, sql.Named("foo", "bar"))
+	_, err := New(da, "", "").Query(nil, `SELECT :foo`, sql.Named("foo", "bar"))
 	if err == nil {
 		t.Fatalf("got: %v", err)
 	}
@@ -125,7 +125,7 @@ func TestDBBeginOK(t *testing.T) {
 		t.Fatalf("got: %v", act)
 	}
 
-	if tx == nil || tx.(*daTx).id != "1234" || tx.(*daTx).ctx != ctx {
+	if tx == nil || tx.(*daTx).id != "1234" || tx.(*daTx).ctx.Err() != nil {
 		t.Fatalf("got: %v", tx)
 	}
 }
@@ -152,7 +152,7 @@ func TestDBBatch(t *testing.T) {
 	da, ctx := &stubDA{nextBESO: beso}, context.Background()
 	db := New(da, "arn:aws:rds:", "arn:aws:secret:")
 
-	b := NewBatch(`UPDATE * WHERE bar = :foos`).
+	b := NewBatch(`UPDATE * WHERE bar = :foo`).
 		Exec(sql.Named("foo", "foo1")).
 		Query(sql.Named("foo", "foo1"))
 
@@ -167,7 +167,7 @@ func TestDBBatch(t *testing.T) {
 }
 
 func TestDBBatchArgErr(t *testing.T) {
-	b := NewBatch(`UPDATE * WHERE bar = :foos`).Query(sql.Named("foo", func() {}))
+	b := NewBatch(`UPDATE * WHERE bar = :foo`).Query(sql.Named("foo", func() {}))
 
 	_, err := New(nil, "", "").ExecBatch(nil, b)
 	if err == nil {
@@ -182,7 +182,7 @@ func TestDBBatchArgErr(t *testing.T) {
 
 func TestDBBatchAwsErr(t *testing.T) {
 	da := &stubDA{nextBESOE: awserr.New("400", "foo", nil)}
-	b := NewBatch(`UPDATE * WHERE bar = :foos`).Exec(sql.Named("foo", "bar"))
+	b := NewBatch(`UPDATE * WHERE bar = :foo`).Exec(sql.Named("foo", "bar"))
 
 	_, err := New(da, "", "").ExecBatch(nil, b)
 	if err == nil {
@@ -194,3 +194,28 @@ func TestDBBatchAwsErr(t *testing.T) {
 		t.Fatalf("got: %T", err)
 	}
 }
+
+func TestDBDatabaseSchema(t *testing.T) {
+	da, ctx := &stubDA{
+		nextESO:  &rdsdataservice.ExecuteStatementOutput{},
+		nextBTO:  &rdsdataservice.BeginTransactionOutput{},
+		nextBESO: &rdsdataservice.BatchExecuteStatementOutput{},
+	}, context.Background()
+	db := New(da, "", "", WithDatabase("app"), WithSchema("public"))
+
+	if _, err := db.Tx(ctx); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if aws.StringValue(da.lastBTI.Database) != "app" || aws.StringValue(da.lastBTI.Schema) != "public" {
+		t.Fatalf("got: %v", da.lastBTI)
+	}
+
+	if _, err := db.ExecBatch(ctx, NewBatch(`DELETE FROM foo`)); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if aws.StringValue(da.lastBESI.Database) != "app" || aws.StringValue(da.lastBESI.Schema) != "public" {
+		t.Fatalf("got: %v", da.lastBESI)
+	}
+}
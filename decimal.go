@@ -0,0 +1,84 @@
+package dasql
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// ratString formats the rational as a decimal number, it fails if the decimal doesn't terminate
+func ratString(r *big.Rat) (string, error) {
+	if r.IsInt() {
+		return r.Num().String(), nil
+	}
+
+	// a fraction has a finite decimal expansion if its denominator only has 2 and 5 as factors,
+	// the number of digits is then the largest of both powers.
+	var n2, n5 int
+	two, five, rem := big.NewInt(2), big.NewInt(5), new(big.Int)
+	d := new(big.Int).Set(r.Denom())
+	for ; rem.Mod(d, two).Sign() == 0; n2++ {
+		d.Quo(d, two)
+	}
+
+	for ; rem.Mod(d, five).Sign() == 0; n5++ {
+		d.Quo(d, five)
+	}
+
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return "", fmt.Errorf("%s has no finite decimal representation", r)
+	}
+
+	if n5 > n2 {
+		n2 = n5
+	}
+
+	return r.FloatString(n2), nil
+}
+
+// scanDecimal parses the decimal string into one of the big number types
+func scanDecimal(s string, dst interface{}) error {
+	var ok bool
+	switch dt := dst.(type) {
+	case *big.Rat:
+		_, ok = dt.SetString(s)
+	case *big.Float:
+		if prec := uint(len(s)) * 4; dt.Prec() == 0 && prec > 64 {
+			dt.SetPrec(prec) // more than the 3.33 bits per decimal digit, SetString would use 64
+		}
+
+		_, ok = dt.SetString(s)
+	case *big.Int:
+		var r *big.Rat
+		if r, ok = new(big.Rat).SetString(s); ok && r.IsInt() {
+			dt.Set(r.Num())
+		} else if ok {
+			return ScanErr{Kind: ScanErrKindTypeMismatch,
+				err: fmt.Errorf("decimal '%s' is not an integer", s)}
+		}
+	}
+
+	if !ok {
+		return ScanErr{Kind: ScanErrKindTypeMismatch,
+			err: fmt.Errorf("failed to parse '%s' as a decimal into %T", s, dst)}
+	}
+
+	return nil
+}
+
+// scanFloatDecimal sets the float as the value of one of the big number types
+func scanFloatDecimal(f float64, dst interface{}) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return ScanErr{Kind: ScanErrKindTypeMismatch,
+			err: fmt.Errorf("non-finite float %v can't be scanned into %T", f, dst)}
+	}
+
+	switch dt := dst.(type) {
+	case *big.Rat:
+		dt.SetFloat64(f)
+	case *big.Float:
+		dt.SetFloat64(f)
+	}
+
+	return nil
+}
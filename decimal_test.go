@@ -0,0 +1,212 @@
+package dasql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestRatString(t *testing.T) {
+	for i, c := range []struct {
+		r   *big.Rat
+		exp string
+	}{
+		{big.NewRat(12, 1), "12"},
+		{big.NewRat(-123, 10), "-12.3"},
+		{big.NewRat(1, 8), "0.125"},
+		{big.NewRat(1, 40), "0.025"},
+		{big.NewRat(1, 3), ""},
+		{big.NewRat(1, 6), ""},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			act, err := ratString(c.r)
+			if c.exp == "" {
+				if err == nil {
+					t.Fatalf("got: %v", act)
+				}
+
+				return
+			}
+
+			if err != nil || act != c.exp {
+				t.Fatalf("exp: %v got: %v (%v)", c.exp, act, err)
+			}
+		})
+	}
+}
+
+func TestScanDecimalPrecision(t *testing.T) {
+	s := "12345678901234567890.123456789"
+	f := new(big.Float)
+	if err := defaultCodec.scanField(&rdsdataservice.Field{StringValue: aws.String(s)}, f); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := f.Text('f', 9); act != s {
+		t.Fatalf("got: %v", act)
+	}
+
+	// a precision that is set is kept
+	f = new(big.Float).SetPrec(64)
+	if err := defaultCodec.scanField(&rdsdataservice.Field{StringValue: aws.String(s)}, f); err != nil ||
+		f.Prec() != 64 {
+		t.Fatalf("got: %v %v", f.Prec(), err)
+	}
+}
+
+func TestScanDecimal(t *testing.T) {
+	for i, c := range []struct {
+		f       *rdsdataservice.Field
+		dst     interface{}
+		exp     string
+		expKind ScanErrKind
+	}{
+		{&rdsdataservice.Field{StringValue: aws.String("12.30")}, new(big.Rat), "123/10", 0},
+		{&rdsdataservice.Field{StringValue: aws.String("12.30")}, new(big.Float), "12.3", 0},
+		{&rdsdataservice.Field{StringValue: aws.String("12.00")}, new(big.Int), "12", 0},
+		{&rdsdataservice.Field{StringValue: aws.String("12.30")}, new(big.Int), "",
+			ScanErrKindTypeMismatch},
+		{&rdsdataservice.Field{StringValue: aws.String("foo")}, new(big.Rat), "",
+			ScanErrKindTypeMismatch},
+		{&rdsdataservice.Field{LongValue: aws.Int64(12)}, new(big.Int), "12", 0},
+		{&rdsdataservice.Field{LongValue: aws.Int64(12)}, new(big.Rat), "12/1", 0},
+		{&rdsdataservice.Field{DoubleValue: aws.Float64(0.5)}, new(big.Rat), "1/2", 0},
+		{&rdsdataservice.Field{DoubleValue: aws.Float64(0.5)}, new(big.Float), "0.5", 0},
+		{&rdsdataservice.Field{DoubleValue: aws.Float64(0.5)}, new(big.Int), "",
+			ScanErrKindTypeMismatch},
+
+		// any text unmarshaler can be scanned from a string
+		{&rdsdataservice.Field{StringValue: aws.String("foo")}, new(testText), "FOO", 0},
+		{&rdsdataservice.Field{StringValue: aws.String("")}, new(testText), "",
+			ScanErrKindTypeMismatch},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := defaultCodec.scanField(c.f, c.dst)
+
+			var se ScanErr
+			if c.expKind != 0 {
+				if !errors.As(err, &se) || se.Kind != c.expKind {
+					t.Fatalf("exp: %v got: %v", c.expKind, err)
+				}
+
+				return
+			}
+
+			if act := c.dst.(interface{ String() string }).String(); err != nil || act != c.exp {
+				t.Fatalf("exp: %v got: %v (%v)", c.exp, act, err)
+			}
+		})
+	}
+}
+
+// testText implements encoding.TextUnmarshaler
+type testText string
+
+func (tt *testText) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		return errors.New("empty")
+	}
+
+	*tt = testText(strings.ToUpper(string(b)))
+	return nil
+}
+
+func (tt *testText) String() string { return string(*tt) }
+
+func TestDecimalReturnType(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{}}, context.Background()
+	db := New(da, "", "", WithDecimalReturnType(rdsdataservice.DecimalReturnTypeDoubleOrLong))
+
+	if _, err := db.Query(ctx, `SELECT price FROM foo WHERE price > :p`,
+		sql.Named("p", big.NewRat(1, 4))); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.ResultSetOptions.DecimalReturnType); act != "DOUBLE_OR_LONG" {
+		t.Fatalf("got: %v", act)
+	}
+
+	if act := da.lastESI.Parameters; len(act) != 1 || aws.StringValue(act[0].TypeHint) != "DECIMAL" ||
+		aws.StringValue(act[0].Value.StringValue) != "0.25" {
+		t.Fatalf("got: %v", act)
+	}
+
+	// the options can be overwritten per query, also through the driver
+	sdb := Adapt(sql.OpenDB(NewConnector(db)))
+	if _, err := sdb.Query(ctx, `SELECT price FROM foo`, (&rdsdataservice.ResultSetOptions{}).
+		SetDecimalReturnType(rdsdataservice.DecimalReturnTypeString)); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.ResultSetOptions.DecimalReturnType); act != "STRING" {
+		t.Fatalf("got: %v", act)
+	}
+
+	if len(da.lastESI.Parameters) != 0 {
+		t.Fatalf("got: %v", da.lastESI.Parameters)
+	}
+
+	if _, err := New(da, "", "").Exec(ctx, `DELETE FROM foo`); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if da.lastESI.ResultSetOptions != nil {
+		t.Fatalf("got: %v", da.lastESI.ResultSetOptions)
+	}
+
+	// an unknown return type fails before the statement is sent
+	da.lastESI = nil
+	if _, err := New(da, "", "", WithDecimalReturnType("FLOAT")).Query(ctx, `SELECT 1`); err == nil ||
+		!strings.Contains(err.Error(), "invalid decimal return type 'FLOAT'") || da.lastESI != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	// the options are not sent as a parameter of a batch
+	da.nextBESO = &rdsdataservice.BatchExecuteStatementOutput{}
+	if _, err := db.ExecBatch(ctx, NewBatch(`DELETE FROM foo WHERE id = :id`).
+		Exec(sql.Named("id", 1), &rdsdataservice.ResultSetOptions{})); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := da.lastBESI.ParameterSets; len(act) != 1 || len(act[0]) != 1 {
+		t.Fatalf("got: %v", act)
+	}
+}
+
+func TestLocalDADecimalReturnType(t *testing.T) {
+	stub, lda := newLocalStub(DialectMySQL)
+	stub.nextESO = &rdsdataservice.ExecuteStatementOutput{
+		ColumnMetadata: []*rdsdataservice.ColumnMetadata{
+			{Name: aws.String("a"), TypeName: aws.String("DECIMAL")},
+			{Name: aws.String("b"), TypeName: aws.String("DECIMAL")},
+		},
+		Records: [][]*rdsdataservice.Field{
+			{{StringValue: aws.String("12")}, {StringValue: aws.String("12.5")}},
+		},
+	}
+
+	rows, err := New(lda, "", "", WithDecimalReturnType("DOUBLE_OR_LONG")).
+		Query(context.Background(), `SELECT a, b FROM foo`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var a int64
+	var b float64
+	for rows.Next() {
+		if err = rows.Scan(&a, &b); err != nil {
+			t.Fatalf("got: %v", err)
+		}
+	}
+
+	if a != 12 || b != 12.5 {
+		t.Fatalf("got: %v %v", a, b)
+	}
+}
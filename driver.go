@@ -0,0 +1,457 @@
+package dasql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// DriverName is the name under which the Data API driver is registered with database/sql
+const DriverName = "dataapi"
+
+func init() {
+	sql.Register(DriverName, Driver{})
+}
+
+var (
+	_ driver.Driver        = Driver{}
+	_ driver.DriverContext = Driver{}
+	_ driver.Connector     = &Connector{}
+
+	_ driver.Conn               = &conn{}
+	_ driver.ConnBeginTx        = &conn{}
+	_ driver.ConnPrepareContext = &conn{}
+	_ driver.QueryerContext     = &conn{}
+	_ driver.ExecerContext      = &conn{}
+	_ driver.NamedValueChecker  = &conn{}
+
+	_ driver.Stmt             = &stmt{}
+	_ driver.StmtQueryContext = &stmt{}
+	_ driver.StmtExecContext  = &stmt{}
+	_ driver.Tx               = &driverTx{}
+	_ driver.Rows             = &driverRows{}
+
+	_ driver.RowsColumnTypeDatabaseTypeName = &driverRows{}
+	_ driver.RowsColumnTypeNullable         = &driverRows{}
+	_ driver.RowsColumnTypePrecisionScale   = &driverRows{}
+	_ driver.RowsColumnTypeLength           = &driverRows{}
+	_ driver.RowsColumnTypeScanType         = &driverRows{}
+)
+
+// Driver implements the database/sql driver interfaces on top of the Data API. It is registered
+// as "dataapi" so sql.Open can be used with a DSN (see ParseDSN), the Data API client is then
+// created from the default AWS session. To use a custom DA call sql.OpenDB(NewConnector(db)).
+type Driver struct{}
+
+// Open implements driver.Driver
+func (d Driver) Open(name string) (driver.Conn, error) {
+	c, err := d.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Connect(context.Background())
+}
+
+// OpenConnector implements driver.DriverContext by parsing the name as a DSN
+func (d Driver) OpenConnector(name string) (driver.Connector, error) {
+	cfg, err := ParseDSN(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// retry a lot more then the default since the cluster might need to wake up
+	sess, err := session.NewSession(request.WithRetryer(
+		aws.NewConfig().WithRegion(cfg.RegionOrDefault()),
+		Retryer{client.DefaultRetryer{NumMaxRetries: 10}}))
+	if err != nil {
+		return nil, fmt.Errorf("dasql: failed to setup aws session: %w", err)
+	}
+
+	db, err := cfg.New(rdsdataservice.New(sess))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConnector(db), nil
+}
+
+// Connector implements driver.Connector for a configured DB
+type Connector struct{ db *DB }
+
+// NewConnector creates a connector that can be passed to sql.OpenDB
+func NewConnector(db *DB) *Connector { return &Connector{db} }
+
+// Connect implements driver.Connector. Since the Data API is connectionless this is cheap.
+func (c *Connector) Connect(context.Context) (driver.Conn, error) { return &conn{db: c.db}, nil }
+
+// Driver implements driver.Connector
+func (c *Connector) Driver() driver.Driver { return Driver{} }
+
+// conn implements driver.Conn, it keeps track of the transaction that is currently active
+type conn struct {
+	db *DB
+	tx *daTx
+}
+
+// tid returns the id of the active transaction, or an empty string if there is none
+func (c *conn) tid() string {
+	if c.tx == nil {
+		return ""
+	}
+
+	return c.tx.id
+}
+
+// Prepare implements driver.Conn
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext implements driver.ConnPrepareContext. The Data API has no notion of a prepared
+// statement so the query is just remembered.
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return &stmt{c, query}, nil
+}
+
+// Close implements driver.Conn
+func (c *conn) Close() error { return nil }
+
+// Begin implements driver.Conn
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx implements driver.ConnBeginTx. The Data API doesn't allow configuring the isolation
+// level or read-only mode, so these are refused.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	switch {
+	case c.tx != nil:
+		return nil, errors.New("dasql: transaction already in progress")
+	case opts.Isolation != driver.IsolationLevel(sql.LevelDefault):
+		return nil, errors.New("dasql: isolation levels are not supported")
+	case opts.ReadOnly:
+		return nil, errors.New("dasql: read-only transactions are not supported")
+	}
+
+	tx, err := c.db.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.tx = tx.(*daTx)
+	return &driverTx{c}, nil
+}
+
+// QueryContext implements driver.QueryerContext
+func (c *conn) QueryContext(
+	ctx context.Context, q string, nargs []driver.NamedValue,
+) (driver.Rows, error) {
+	rows, err := c.db.query(ctx, c.tid(), q, namedValueArgs(nargs)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &driverRows{rows.(*daRows)}, nil
+}
+
+// ExecContext implements driver.ExecerContext
+func (c *conn) ExecContext(
+	ctx context.Context, q string, nargs []driver.NamedValue,
+) (driver.Result, error) {
+	return c.db.exec(ctx, c.tid(), q, namedValueArgs(nargs)...)
+}
+
+// CheckNamedValue implements driver.NamedValueChecker. Any value that this package can convert
+// is passed as-is, everything else is left to the default conversion of database/sql.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	switch nv.Value.(type) {
+	case NamedFromArg, *rdsdataservice.ResultSetOptions:
+		return nil // handled by the DB
+	}
+
+	if _, _, err := c.db.codec.convertArg(nv.Value); err != nil {
+		return driver.ErrSkip
+	}
+
+	return nil
+}
+
+// namedValueArgs turns the driver's named values back into arguments for this package
+func namedValueArgs(nargs []driver.NamedValue) []interface{} {
+	args := make([]interface{}, len(nargs))
+	for i, nv := range nargs {
+		if nv.Name == "" {
+			args[i] = nv.Value
+			continue
+		}
+
+		args[i] = sql.Named(nv.Name, nv.Value)
+	}
+
+	return args
+}
+
+// stmt implements driver.Stmt by simply executing the query on every call
+type stmt struct {
+	c *conn
+	q string
+}
+
+// Close implements driver.Stmt
+func (s *stmt) Close() error { return nil }
+
+// NumInput implements driver.Stmt, the number of placeholders is unknown to the driver
+func (s *stmt) NumInput() int { return -1 }
+
+// Exec implements driver.Stmt
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), valueArgs(args))
+}
+
+// Query implements driver.Stmt
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), valueArgs(args))
+}
+
+// ExecContext implements driver.StmtExecContext
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.c.ExecContext(ctx, s.q, args)
+}
+
+// QueryContext implements driver.StmtQueryContext
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.c.QueryContext(ctx, s.q, args)
+}
+
+// valueArgs converts the deprecated value arguments into ordinal named values
+func valueArgs(args []driver.Value) []driver.NamedValue {
+	nargs := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		nargs[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+
+	return nargs
+}
+
+// driverTx implements driver.Tx by ending the connection's transaction
+type driverTx struct{ c *conn }
+
+// Commit implements driver.Tx
+func (tx *driverTx) Commit() error {
+	defer func() { tx.c.tx = nil }()
+	return tx.c.tx.Commit()
+}
+
+// Rollback implements driver.Tx
+func (tx *driverTx) Rollback() error {
+	defer func() { tx.c.tx = nil }()
+	return tx.c.tx.Rollback()
+}
+
+// driverRows implements driver.Rows on top of the Data API records
+type driverRows struct{ rows *daRows }
+
+// Columns implements driver.Rows. If the Data API didn't return column metadata the columns are
+// named after their position in the first record.
+func (r *driverRows) Columns() []string {
+	if cols, err := r.rows.Columns(); err == nil {
+		return cols
+	}
+
+	if len(r.rows.recs) < 1 {
+		return nil
+	}
+
+	cols := make([]string, len(r.rows.recs[0]))
+	for i := range cols {
+		cols[i] = "col" + strconv.Itoa(i)
+	}
+
+	return cols
+}
+
+// columnType returns the column type at 'i', it is nil if there is no column metadata
+func (r *driverRows) columnType(i int) ColumnType {
+	if i >= len(r.rows.cols) {
+		return nil
+	}
+
+	return daColumnType{r.rows.cols[i]}
+}
+
+// ColumnTypeDatabaseTypeName implements driver.RowsColumnTypeDatabaseTypeName
+func (r *driverRows) ColumnTypeDatabaseTypeName(i int) string {
+	if ct := r.columnType(i); ct != nil {
+		return ct.DatabaseTypeName()
+	}
+
+	return ""
+}
+
+// ColumnTypeNullable implements driver.RowsColumnTypeNullable
+func (r *driverRows) ColumnTypeNullable(i int) (nullable, ok bool) {
+	if ct := r.columnType(i); ct != nil {
+		return ct.Nullable()
+	}
+
+	return false, false
+}
+
+// ColumnTypePrecisionScale implements driver.RowsColumnTypePrecisionScale
+func (r *driverRows) ColumnTypePrecisionScale(i int) (precision, scale int64, ok bool) {
+	if ct := r.columnType(i); ct != nil {
+		return ct.DecimalSize()
+	}
+
+	return 0, 0, false
+}
+
+// ColumnTypeLength implements driver.RowsColumnTypeLength
+func (r *driverRows) ColumnTypeLength(i int) (length int64, ok bool) {
+	if ct := r.columnType(i); ct != nil {
+		return ct.Length()
+	}
+
+	return 0, false
+}
+
+// ColumnTypeScanType implements driver.RowsColumnTypeScanType
+func (r *driverRows) ColumnTypeScanType(i int) reflect.Type {
+	if ct := r.columnType(i); ct != nil {
+		return ct.ScanType()
+	}
+
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+// Close implements driver.Rows
+func (r *driverRows) Close() error { return r.rows.Close() }
+
+// Next implements driver.Rows
+func (r *driverRows) Next(dest []driver.Value) (err error) {
+	if !r.rows.Next() {
+		return io.EOF
+	}
+
+	for i, f := range r.rows.recs[r.rows.pos] {
+		if i >= len(dest) {
+			break
+		}
+
+		dest[i], err = fieldValue(f)
+		if err != nil {
+			return ScanErr{Kind: ScanErrKindUnsupported, err: err, Row: r.rows.pos, Field: i}
+		}
+	}
+
+	return nil
+}
+
+// fieldValue turns a Data API field into a value as returned by a database/sql driver
+func fieldValue(f *rdsdataservice.Field) (driver.Value, error) {
+	switch {
+	case aws.BoolValue(f.IsNull):
+		return nil, nil
+	case f.StringValue != nil:
+		return *f.StringValue, nil
+	case f.LongValue != nil:
+		return *f.LongValue, nil
+	case f.DoubleValue != nil:
+		return *f.DoubleValue, nil
+	case f.BooleanValue != nil:
+		return *f.BooleanValue, nil
+	case f.BlobValue != nil:
+		return f.BlobValue, nil
+	case f.ArrayValue != nil:
+		return arrayLiteral(f.ArrayValue)
+	default:
+		return nil, fmt.Errorf("unsupported field: %s", f.String())
+	}
+}
+
+// arrayEscaper escapes the quoted elements of an array literal
+var arrayEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// arrayLiteral encodes a (multi-dimensional) array value as a Postgres array literal. Slices are
+// not valid driver values, a literal can be scanned into a string or by an array type of a
+// Postgres driver, and passed to one as an argument.
+func arrayLiteral(av *rdsdataservice.ArrayValue) (string, error) {
+	var elems []string
+	switch {
+	case av.StringValues != nil:
+		for _, v := range av.StringValues {
+			elems = append(elems, `"`+arrayEscaper.Replace(aws.StringValue(v))+`"`)
+		}
+	case av.LongValues != nil:
+		for _, v := range av.LongValues {
+			elems = append(elems, strconv.FormatInt(aws.Int64Value(v), 10))
+		}
+	case av.DoubleValues != nil:
+		for _, v := range av.DoubleValues {
+			elems = append(elems, strconv.FormatFloat(aws.Float64Value(v), 'g', -1, 64))
+		}
+	case av.BooleanValues != nil:
+		for _, v := range av.BooleanValues {
+			if aws.BoolValue(v) {
+				elems = append(elems, "t")
+			} else {
+				elems = append(elems, "f")
+			}
+		}
+	case av.ArrayValues != nil:
+		for _, av := range av.ArrayValues {
+			elem, err := arrayLiteral(av)
+			if err != nil {
+				return "", err
+			}
+
+			elems = append(elems, elem)
+		}
+	default:
+		return "", fmt.Errorf("unsupported array value: %s", av.String())
+	}
+
+	return "{" + strings.Join(elems, ",") + "}", nil
+}
+
+// arrayValue turns a (multi-dimensional) array value into a Go slice, for scanning into an
+// *interface{} with this package
+func arrayValue(av *rdsdataservice.ArrayValue) (interface{}, error) {
+	switch {
+	case av.StringValues != nil:
+		return aws.StringValueSlice(av.StringValues), nil
+	case av.LongValues != nil:
+		return aws.Int64ValueSlice(av.LongValues), nil
+	case av.DoubleValues != nil:
+		return aws.Float64ValueSlice(av.DoubleValues), nil
+	case av.BooleanValues != nil:
+		return aws.BoolValueSlice(av.BooleanValues), nil
+	case av.ArrayValues != nil:
+		vs := make([]interface{}, len(av.ArrayValues))
+		for i, av := range av.ArrayValues {
+			v, err := arrayValue(av)
+			if err != nil {
+				return nil, err
+			}
+
+			vs[i] = v
+		}
+
+		return vs, nil
+	default:
+		return nil, fmt.Errorf("unsupported array value: %s", av.String())
+	}
+}
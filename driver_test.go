@@ -0,0 +1,255 @@
+package dasql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestDriverQuery(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{
+		Records: [][]*rdsdataservice.Field{
+			{{StringValue: aws.String("foo")}, {LongValue: aws.Int64(1)}},
+			{{StringValue: aws.String("bar")}, {IsNull: aws.Bool(true)}},
+		},
+	}}, context.Background()
+
+	db := sql.OpenDB(NewConnector(New(da, "arn:aws:rds:", "arn:aws:secret:")))
+	rows, err := db.QueryContext(ctx, `SELECT * FROM foo WHERE bar = :bar`, sql.Named("bar", "rab"))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	defer rows.Close()
+
+	var names []string
+	var nrs []sql.NullInt64
+	for rows.Next() {
+		var name string
+		var nr sql.NullInt64
+		if err := rows.Scan(&name, &nr); err != nil {
+			t.Fatalf("got: %v", err)
+		}
+
+		names, nrs = append(names, name), append(nrs, nr)
+	}
+
+	if err := rows.Err(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if len(names) != 2 || names[0] != "foo" || names[1] != "bar" {
+		t.Fatalf("got: %v", names)
+	}
+
+	if len(nrs) != 2 || nrs[0].Int64 != 1 || nrs[1].Valid {
+		t.Fatalf("got: %v", nrs)
+	}
+
+	if len(da.lastESI.Parameters) != 1 || aws.StringValue(da.lastESI.Parameters[0].Name) != "bar" {
+		t.Fatalf("got: %v", da.lastESI.Parameters)
+	}
+}
+
+func TestDriverQueryArray(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{
+		Records: [][]*rdsdataservice.Field{{{ArrayValue: &rdsdataservice.ArrayValue{
+			LongValues: aws.Int64Slice([]int64{1, 2})}}}},
+	}}, context.Background()
+
+	db := sql.OpenDB(NewConnector(New(da, "arn:aws:rds:", "arn:aws:secret:")))
+	var act string
+	if err := db.QueryRowContext(ctx, `SELECT ids FROM foo`).Scan(&act); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act != "{1,2}" {
+		t.Fatalf("got: %v", act)
+	}
+}
+
+func TestDriverExec(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{
+		NumberOfRecordsUpdated: aws.Int64(1),
+		GeneratedFields:        []*rdsdataservice.Field{{LongValue: aws.Int64(42)}},
+	}}, context.Background()
+
+	db := sql.OpenDB(NewConnector(New(da, "arn:aws:rds:", "arn:aws:secret:")))
+	res, err := db.ExecContext(ctx, `INSERT INTO foo (bar) VALUES (:bar)`, sql.Named("bar", 1))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if id, _ := res.LastInsertId(); id != 42 {
+		t.Fatalf("got: %v", id)
+	}
+
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Fatalf("got: %v", n)
+	}
+
+	if act := aws.Int64Value(da.lastESI.Parameters[0].Value.LongValue); act != 1 {
+		t.Fatalf("got: %v", act)
+	}
+
+	if _, err = db.ExecContext(ctx, `UPDATE foo SET price = :p`, sql.Named("p", testMoney(1250))); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.Parameters[0].Value.StringValue); act != "12.50" {
+		t.Fatalf("got: %v", act)
+	}
+}
+
+func TestDriverTx(t *testing.T) {
+	da, ctx := &stubDA{
+		nextBTO: &rdsdataservice.BeginTransactionOutput{TransactionId: aws.String("1234")},
+		nextESO: &rdsdataservice.ExecuteStatementOutput{},
+	}, context.Background()
+
+	db := sql.OpenDB(NewConnector(New(da, "arn:aws:rds:", "arn:aws:secret:")))
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM foo`); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.TransactionId); act != "1234" {
+		t.Fatalf("got: %v", act)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastCTI.TransactionId); act != "1234" {
+		t.Fatalf("got: %v", act)
+	}
+
+	if _, err = db.ExecContext(ctx, `DELETE FROM foo`); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if da.lastESI.TransactionId != nil {
+		t.Fatalf("got: %v", da.lastESI.TransactionId)
+	}
+
+	if _, err = db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true}); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestDriverPrepared(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{}}, context.Background()
+	db := sql.OpenDB(NewConnector(New(da, "arn:aws:rds:", "arn:aws:secret:")))
+
+	stmt, err := db.PrepareContext(ctx, `UPDATE foo SET bar = :bar`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	defer stmt.Close()
+
+	if _, err = stmt.ExecContext(ctx, sql.Named("bar", []byte{0x01})); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.Sql); act != `UPDATE foo SET bar = :bar` {
+		t.Fatalf("got: %v", act)
+	}
+}
+
+func TestDriverOpenByName(t *testing.T) {
+	if _, err := sql.Open(DriverName, ""); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	db, err := sql.Open(DriverName, "dataapi://"+testResourceARN+"?secret="+testSecretARN)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestFieldValue(t *testing.T) {
+	for _, c := range []struct {
+		f   *rdsdataservice.Field
+		exp driver.Value
+	}{
+		{&rdsdataservice.Field{IsNull: aws.Bool(true)}, nil},
+		{&rdsdataservice.Field{StringValue: aws.String("foo")}, "foo"},
+		{&rdsdataservice.Field{LongValue: aws.Int64(1)}, int64(1)},
+		{&rdsdataservice.Field{DoubleValue: aws.Float64(1.5)}, 1.5},
+		{&rdsdataservice.Field{BooleanValue: aws.Bool(true)}, true},
+	} {
+		act, err := fieldValue(c.f)
+		if err != nil || act != c.exp {
+			t.Fatalf("exp: %v got: %v (%v)", c.exp, act, err)
+		}
+	}
+
+	for _, c := range []struct {
+		av  *rdsdataservice.ArrayValue
+		exp driver.Value
+	}{
+		{&rdsdataservice.ArrayValue{ArrayValues: []*rdsdataservice.ArrayValue{
+			{LongValues: aws.Int64Slice([]int64{1, 2})}, {LongValues: aws.Int64Slice([]int64{3})}}}, "{{1,2},{3}}"},
+		{&rdsdataservice.ArrayValue{StringValues: aws.StringSlice([]string{"a", `b "c"`, `d\`})}, `{"a","b \"c\"","d\\"}`},
+		{&rdsdataservice.ArrayValue{DoubleValues: aws.Float64Slice([]float64{1.5})}, "{1.5}"},
+		{&rdsdataservice.ArrayValue{BooleanValues: aws.BoolSlice([]bool{true, false})}, "{t,f}"},
+		{&rdsdataservice.ArrayValue{StringValues: aws.StringSlice([]string{})}, "{}"},
+	} {
+		act, err := fieldValue(&rdsdataservice.Field{ArrayValue: c.av})
+		if err != nil || act != c.exp {
+			t.Fatalf("exp: %v got: %v (%v)", c.exp, act, err)
+		}
+
+		if !driver.IsValue(act) {
+			t.Fatalf("got: %T", act)
+		}
+	}
+
+	if _, err := fieldValue(&rdsdataservice.Field{ArrayValue: &rdsdataservice.ArrayValue{}}); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err := fieldValue(&rdsdataservice.Field{}); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestDriverDSNDialect(t *testing.T) {
+	cfg, err := ParseDSN("dataapi://" + testResourceARN + "?secret=" + testSecretARN + "&dialect=mysql")
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{}}, context.Background()
+	db, err := cfg.New(da)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	sdb := sql.OpenDB(NewConnector(db))
+	if _, err = sdb.ExecContext(ctx, `DELETE FROM foo WHERE id = ?`, 1); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.Sql); act != `DELETE FROM foo WHERE id = :p1` {
+		t.Fatalf("got: %v", act)
+	}
+
+	if act := da.lastESI.Parameters; len(act) != 1 || aws.Int64Value(act[0].Value.LongValue) != 1 {
+		t.Fatalf("got: %v", act)
+	}
+}
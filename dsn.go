@@ -0,0 +1,155 @@
+package dasql
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+)
+
+// dsnScheme is the scheme that each Data API DSN starts with
+const dsnScheme = "dataapi://"
+
+// dsnDialects maps the values of the 'dialect' DSN parameter to a dialect
+var dsnDialects = map[string]Dialect{"mysql": DialectMySQL, "postgres": DialectPostgres}
+
+// Config holds everything that is needed to setup a DB. It can be parsed from a DSN that looks
+// like: dataapi://<resourceArn>?secret=<secretArn>&database=app&schema=public&region=eu-west-1
+type Config struct {
+	ResourceARN string
+	SecretARN   string
+	Database    string
+	Schema      string
+
+	// Region of the Data API endpoint, if empty the region from the resource ARN is used
+	Region string
+
+	// Dialect of the positional placeholders, set with the 'dialect' parameter as either 'mysql'
+	// or 'postgres'. See WithDialect.
+	Dialect Dialect
+}
+
+// ParseDSN parses and validates a Data API DSN into a config
+func ParseDSN(dsn string) (*Config, error) {
+	if !strings.HasPrefix(dsn, dsnScheme) {
+		return nil, fmt.Errorf("dasql: invalid DSN, must start with '%s'", dsnScheme)
+	}
+
+	rest, rawq := dsn[len(dsnScheme):], ""
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		rest, rawq = rest[:i], rest[i+1:]
+	}
+
+	res, err := url.PathUnescape(rest)
+	if err != nil {
+		return nil, fmt.Errorf("dasql: invalid DSN resource: %w", err)
+	}
+
+	q, err := url.ParseQuery(rawq)
+	if err != nil {
+		return nil, fmt.Errorf("dasql: invalid DSN query: %w", err)
+	}
+
+	cfg := &Config{ResourceARN: res}
+	for k := range q {
+		switch v := q.Get(k); k {
+		case "secret":
+			cfg.SecretARN = v
+		case "database":
+			cfg.Database = v
+		case "schema":
+			cfg.Schema = v
+		case "region":
+			cfg.Region = v
+		case "dialect":
+			d, ok := dsnDialects[v]
+			if !ok {
+				return nil, fmt.Errorf("dasql: invalid DSN, unknown dialect '%s'", v)
+			}
+
+			cfg.Dialect = d
+		default:
+			return nil, fmt.Errorf("dasql: invalid DSN, unknown parameter '%s'", k)
+		}
+	}
+
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// String formats the config as a DSN that can be parsed by ParseDSN
+func (c Config) String() string {
+	q := url.Values{}
+	for k, v := range map[string]string{
+		"secret":   c.SecretARN,
+		"database": c.Database,
+		"schema":   c.Schema,
+		"region":   c.Region,
+	} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+
+	for v, d := range dsnDialects {
+		if d == c.Dialect {
+			q.Set("dialect", v)
+		}
+	}
+
+	dsn := dsnScheme + url.PathEscape(c.ResourceARN)
+	if len(q) > 0 {
+		dsn += "?" + q.Encode()
+	}
+
+	return dsn
+}
+
+// Validate checks that the resource and secret are valid ARNs for an Aurora cluster and a
+// Secrets Manager secret.
+func (c Config) Validate() error {
+	res, err := arn.Parse(c.ResourceARN)
+	if err != nil {
+		return fmt.Errorf("dasql: invalid resource ARN: %w", err)
+	}
+
+	if res.Service != "rds" || !strings.HasPrefix(res.Resource, "cluster:") {
+		return errors.New("dasql: invalid resource ARN, must be an rds cluster")
+	}
+
+	sec, err := arn.Parse(c.SecretARN)
+	if err != nil {
+		return fmt.Errorf("dasql: invalid secret ARN: %w", err)
+	}
+
+	if sec.Service != "secretsmanager" || !strings.HasPrefix(sec.Resource, "secret:") {
+		return errors.New("dasql: invalid secret ARN, must be a secretsmanager secret")
+	}
+
+	return nil
+}
+
+// RegionOrDefault returns the configured region, or the region of the resource ARN if it's empty
+func (c Config) RegionOrDefault() string {
+	if c.Region != "" {
+		return c.Region
+	}
+
+	res, _ := arn.Parse(c.ResourceARN)
+	return res.Region
+}
+
+// New validates the config and creates a DB that uses 'da' to call the Data API
+func (c Config) New(da DA) (*DB, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return New(da, c.ResourceARN, c.SecretARN,
+		WithDatabase(c.Database), WithSchema(c.Schema), WithDialect(c.Dialect)), nil
+}
@@ -0,0 +1,100 @@
+package dasql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+const (
+	testResourceARN = "arn:aws:rds:eu-west-1:123456789012:cluster:app"
+	testSecretARN   = "arn:aws:secretsmanager:eu-west-1:123456789012:secret:app-AbCdEf"
+)
+
+func TestParseDSN(t *testing.T) {
+	dsn := "dataapi://" + testResourceARN + "?secret=" + testSecretARN +
+		"&database=app&schema=public&region=us-east-1&dialect=postgres"
+
+	cfg, err := ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if cfg.ResourceARN != testResourceARN || cfg.SecretARN != testSecretARN ||
+		cfg.Database != "app" || cfg.Schema != "public" || cfg.Region != "us-east-1" ||
+		cfg.Dialect != DialectPostgres {
+		t.Fatalf("got: %+v", cfg)
+	}
+
+	cfg2, err := ParseDSN(cfg.String())
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if *cfg2 != *cfg {
+		t.Fatalf("exp: %+v got: %+v", cfg, cfg2)
+	}
+}
+
+func TestParseDSNErrors(t *testing.T) {
+	for _, c := range []struct {
+		dsn    string
+		expErr string
+	}{
+		{"mysql://foo", "must start with"},
+		{"dataapi://" + testResourceARN + "?secret=" + testSecretARN + "&foo=bar", "unknown parameter"},
+		{"dataapi://" + testResourceARN + "?secret=%zz", "invalid DSN query"},
+		{"dataapi://" + testResourceARN + "?secret=" + testSecretARN + "&dialect=oracle", "unknown dialect"},
+		{"dataapi://foo?secret=" + testSecretARN, "invalid resource ARN"},
+		{"dataapi://" + testSecretARN + "?secret=" + testSecretARN, "must be an rds cluster"},
+		{"dataapi://" + testResourceARN, "invalid secret ARN"},
+		{"dataapi://" + testResourceARN + "?secret=" + testResourceARN, "must be a secretsmanager"},
+	} {
+		t.Run(c.dsn, func(t *testing.T) {
+			_, err := ParseDSN(c.dsn)
+			if err == nil || !strings.Contains(err.Error(), c.expErr) {
+				t.Fatalf("exp: %v got: %v", c.expErr, err)
+			}
+		})
+	}
+}
+
+func TestConfigRegion(t *testing.T) {
+	cfg := Config{ResourceARN: testResourceARN}
+	if act := cfg.RegionOrDefault(); act != "eu-west-1" {
+		t.Fatalf("got: %v", act)
+	}
+
+	cfg.Region = "us-east-1"
+	if act := cfg.RegionOrDefault(); act != "us-east-1" {
+		t.Fatalf("got: %v", act)
+	}
+}
+
+func TestConfigNew(t *testing.T) {
+	if _, err := (Config{}).New(&stubDA{}); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{}}, context.Background()
+	db, err := Config{
+		ResourceARN: testResourceARN,
+		SecretARN:   testSecretARN,
+		Database:    "app",
+		Schema:      "public",
+	}.New(da)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err = db.Exec(ctx, `DELETE FROM foo`); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if aws.StringValue(da.lastESI.Database) != "app" || aws.StringValue(da.lastESI.Schema) != "public" {
+		t.Fatalf("got: %v", da.lastESI)
+	}
+}
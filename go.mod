@@ -4,5 +4,6 @@ go 1.15
 
 require (
 	github.com/aws/aws-sdk-go v1.35.24
-	github.com/go-sql-driver/mysql v1.5.0 //testOnly
+	github.com/go-sql-driver/mysql v1.5.0
+	github.com/mattn/go-sqlite3 v1.14.6
 )
@@ -0,0 +1,186 @@
+package dasql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// isList returns whether the argument is a slice or array that should be expanded into a list of
+// placeholders. Byte slices and types with a conversion of their own are never expanded.
+func isList(arg interface{}, types *TypeRegistry) (reflect.Value, bool) {
+	rv := reflect.ValueOf(arg)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return rv, false
+	}
+
+	switch arg.(type) {
+	case driver.Valuer, encoding.TextMarshaler:
+		return rv, false
+	}
+
+	return rv, rv.Type().Elem().Kind() != reflect.Uint8 && types.encoder(arg) == nil
+}
+
+// listName returns the parameter name of element 'i' of the list argument 'name'
+func listName(name string, i int) string { return name + "_" + strconv.Itoa(i) }
+
+// expandLists expands the named placeholders of slice arguments into a list of placeholders with
+// one argument for each element, so 'id IN (:ids)' becomes 'id IN (:ids_0, :ids_1)'. The Data API
+// can't bind arrays in an IN list. Only placeholders that are the only element of an IN (or NOT IN)
+// list are expanded, others such as '= ANY(:ids)' or an array column value are bound as an array.
+// An empty list rewrites the comparison into one that is false (or true). If max is larger than
+// zero, lists with more elements are rejected.
+func expandLists(
+	q string, d Dialect, max int, types *TypeRegistry, args []interface{},
+) (string, []interface{}, error) {
+	lists, names := map[string]reflect.Value{}, map[string]bool{}
+	for _, arg := range args {
+		if named, ok := arg.(sql.NamedArg); ok {
+			names[named.Name] = true
+			if rv, ok := isList(named.Value, types); ok {
+				lists[named.Name] = rv
+			}
+		}
+	}
+
+	if len(lists) == 0 {
+		return q, args, nil
+	}
+
+	var b strings.Builder
+	var last int
+	expanded, empty, kept := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, ph := range scanPlaceholders(q, d) {
+		rv, ok := lists[ph.name]
+		if ph.kind != placeholderNamed || !ok {
+			continue
+		}
+
+		start, end, not, ok := inList(q, ph)
+		if !ok {
+			kept[ph.name] = true
+			continue
+		}
+
+		if max > 0 && rv.Len() > max {
+			return "", nil, ArgErr{Kind: ArgErrKindListSize, Names: []string{ph.name}}
+		}
+
+		if rv.Len() == 0 {
+			b.WriteString(q[last:start])
+			b.WriteString(emptyIn(d, not))
+			last, empty[ph.name] = end, true
+			continue
+		}
+
+		b.WriteString(q[last:ph.start])
+		for i := 0; i < rv.Len(); i++ {
+			if names[listName(ph.name, i)] {
+				return "", nil, ArgErr{Kind: ArgErrKindDuplicate, Names: []string{listName(ph.name, i)}}
+			}
+
+			if i > 0 {
+				b.WriteString(", ")
+			}
+
+			b.WriteString(":" + listName(ph.name, i))
+		}
+
+		last, expanded[ph.name] = ph.end, true
+	}
+
+	b.WriteString(q[last:])
+
+	nargs := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		named, ok := arg.(sql.NamedArg)
+		if ok && expanded[named.Name] {
+			rv := lists[named.Name]
+			for i := 0; i < rv.Len(); i++ {
+				nargs = append(nargs, sql.Named(listName(named.Name, i), rv.Index(i).Interface()))
+			}
+		}
+
+		// a list that is also used outside an IN list is still bound as a whole, otherwise
+		// there is nothing left to bind once it is expanded or its comparison is rewritten
+		if !ok || kept[named.Name] || (!expanded[named.Name] && !empty[named.Name]) {
+			nargs = append(nargs, arg)
+		}
+	}
+
+	return b.String(), nargs, nil
+}
+
+// inList returns the start and end offset of the 'IN (:name)' comparison around the placeholder,
+// and whether it is negated with NOT. It returns false if the placeholder is not the only element
+// of an IN list.
+func inList(q string, ph placeholder) (start, end int, not, ok bool) {
+	end = skipSpace(q, ph.end)
+	if end >= len(q) || q[end] != ')' {
+		return 0, 0, false, false
+	}
+
+	start = skipSpaceBack(q, ph.start)
+	if start == 0 || q[start-1] != '(' {
+		return 0, 0, false, false
+	}
+
+	if start, ok = wordBack(q, skipSpaceBack(q, start-1), "IN"); !ok {
+		return 0, 0, false, false
+	}
+
+	if nstart, ok := wordBack(q, skipSpaceBack(q, start), "NOT"); ok {
+		start, not = nstart, true
+	}
+
+	return start, end + 1, not, true
+}
+
+// emptyIn returns the comparison that replaces an IN list without elements. Postgres compares to
+// an empty array so the element type is inferred, others use a subquery without rows.
+func emptyIn(d Dialect, not bool) string {
+	switch {
+	case d == DialectPostgres && not:
+		return "<> ALL('{}')"
+	case d == DialectPostgres:
+		return "= ANY('{}')"
+	case not:
+		return "NOT IN (SELECT NULL FROM (SELECT 1) AS dasql_empty WHERE 1=0)"
+	default:
+		return "IN (SELECT NULL FROM (SELECT 1) AS dasql_empty WHERE 1=0)"
+	}
+}
+
+// skipSpace returns the position of the first non-space character at or after 'i'
+func skipSpace(q string, i int) int {
+	for i < len(q) && isSpace(q[i]) {
+		i++
+	}
+
+	return i
+}
+
+// skipSpaceBack returns the position after the last non-space character before 'i'
+func skipSpaceBack(q string, i int) int {
+	for i > 0 && isSpace(q[i-1]) {
+		i--
+	}
+
+	return i
+}
+
+// wordBack returns the start of the keyword 'w' if it ends right before 'i', case insensitive
+func wordBack(q string, i int, w string) (int, bool) {
+	start := i - len(w)
+	if start < 0 || !strings.EqualFold(q[start:i], w) || (start > 0 && isIdentChar(q[start-1])) {
+		return 0, false
+	}
+
+	return start, true
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
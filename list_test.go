@@ -0,0 +1,124 @@
+package dasql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestExpandLists(t *testing.T) {
+	for i, c := range []struct {
+		q       string
+		d       Dialect
+		max     int
+		args    []interface{}
+		exp     string
+		expArgs string
+		expErr  error
+	}{
+		{
+			q: `SELECT * FROM foo WHERE id IN (:ids) AND a = :a`, args: []interface{}{
+				sql.Named("ids", []int64{1, 2}), sql.Named("a", "x")},
+			exp:     `SELECT * FROM foo WHERE id IN (:ids_0, :ids_1) AND a = :a`,
+			expArgs: `[{{} ids_0 1} {{} ids_1 2} {{} a x}]`,
+		},
+		{
+			q: `SELECT * FROM foo WHERE a IN (:a) OR b IN (:a, ':a')`, args: []interface{}{
+				sql.Named("a", []string{"x"})},
+			exp:     `SELECT * FROM foo WHERE a IN (:a_0) OR b IN (:a, ':a')`,
+			expArgs: `[{{} a_0 x} {{} a [x]}]`,
+		},
+		{
+			q: `SELECT * FROM foo WHERE id IN ( :ids )`, args: []interface{}{
+				sql.Named("ids", []int{})},
+			exp:     `SELECT * FROM foo WHERE id IN (SELECT NULL FROM (SELECT 1) AS dasql_empty WHERE 1=0)`,
+			expArgs: `[]`,
+		},
+		{
+			q: "SELECT * FROM foo WHERE id not\nin (:ids)", args: []interface{}{
+				sql.Named("ids", []int{})},
+			exp:     `SELECT * FROM foo WHERE id NOT IN (SELECT NULL FROM (SELECT 1) AS dasql_empty WHERE 1=0)`,
+			expArgs: `[]`,
+		},
+		{
+			q: `SELECT * FROM foo WHERE id IN (:ids) AND b NOT IN (:ids)`, d: DialectPostgres,
+			args:    []interface{}{sql.Named("ids", []int{})},
+			exp:     `SELECT * FROM foo WHERE id = ANY('{}') AND b <> ALL('{}')`,
+			expArgs: `[]`,
+		},
+		{
+			q: `SELECT * FROM foo WHERE id = :id`, args: []interface{}{
+				sql.Named("id", []byte("foo")), sql.Named("ids", []int{1})},
+			exp:     `SELECT * FROM foo WHERE id = :id`,
+			expArgs: `[{{} id [102 111 111]} {{} ids [1]}]`,
+		},
+		{
+			q: `INSERT INTO foo (a) VALUES (:a)`, args: []interface{}{sql.Named("a", []string{})},
+			exp:     `INSERT INTO foo (a) VALUES (:a)`,
+			expArgs: `[{{} a []}]`,
+		},
+		{
+			q: `SELECT * FROM foo WHERE id = ANY(:ids)`, d: DialectPostgres, args: []interface{}{
+				sql.Named("ids", []int{1, 2})},
+			exp:     `SELECT * FROM foo WHERE id = ANY(:ids)`,
+			expArgs: `[{{} ids [1 2]}]`,
+		},
+		{
+			q: `SELECT * FROM foo WHERE id IN (:ids) AND x = :ids_1`, args: []interface{}{
+				sql.Named("ids", []int{1, 2}), sql.Named("ids_1", 3)},
+			expErr: ArgErr{Kind: ArgErrKindDuplicate, Names: []string{"ids_1"}},
+		},
+		{
+			q: `SELECT * FROM foo WHERE id IN (:ids)`, max: 2, args: []interface{}{
+				sql.Named("ids", []int{1, 2, 3})},
+			expErr: ArgErr{Kind: ArgErrKindListSize, Names: []string{"ids"}},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			q, args, err := expandLists(c.q, c.d, c.max, nil, c.args)
+			if !errors.Is(err, c.expErr) {
+				t.Fatalf("exp: %v got: %v", c.expErr, err)
+			}
+
+			if q != c.exp {
+				t.Fatalf("exp: %v got: %v", c.exp, q)
+			}
+
+			if c.expErr == nil {
+				if act := fmt.Sprint(args); act != c.expArgs {
+					t.Fatalf("exp: %v got: %v", c.expArgs, act)
+				}
+			}
+		})
+	}
+}
+
+func TestDBQueryList(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{}}, context.Background()
+	db := New(da, "", "", WithDialect(DialectMySQL), WithMaxListSize(3))
+
+	if _, err := db.Query(ctx, `SELECT * FROM foo WHERE id IN (?) AND a = ?`, []int64{1, 2}, "x"); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.Sql); act != `SELECT * FROM foo WHERE id IN (:p1_0, :p1_1) AND a = :p2` {
+		t.Fatalf("got: %v", act)
+	}
+
+	if len(da.lastESI.Parameters) != 3 ||
+		aws.StringValue(da.lastESI.Parameters[1].Name) != "p1_1" ||
+		aws.Int64Value(da.lastESI.Parameters[1].Value.LongValue) != 2 {
+		t.Fatalf("got: %v", da.lastESI.Parameters)
+	}
+
+	_, err := db.Query(ctx, `SELECT * FROM foo WHERE id IN (?)`, []int{1, 2, 3, 4})
+	if !errors.Is(err, ArgErr{Kind: ArgErrKindListSize, Names: []string{"p1"}}) {
+		t.Fatalf("got: %v", err)
+	}
+}
@@ -0,0 +1,509 @@
+package dasql
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+var _ DA = &localDA{}
+
+// localDA implements the DA interface by running statements on a database directly
+type localDA struct {
+	db      *sql.DB
+	dialect Dialect
+
+	mu  sync.Mutex
+	txs map[string]*sql.Tx
+}
+
+// LocalDA returns a DA implementation that emulates the Data API by running the statements against
+// a (local) database directly. The named parameters are rewritten into placeholders for the
+// dialect of the database and results are returned as fields and column metadata the way the Data
+// API would. Transactions are identified by generated ids. It allows the DB to be tested end to
+// end without access to AWS. The database and schema of the input are ignored.
+func LocalDA(db *sql.DB, d Dialect) DA {
+	return &localDA{db: db, dialect: d, txs: make(map[string]*sql.Tx)}
+}
+
+// querier is the part of a database that is shared between *sql.DB and *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, q string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, q string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, q string) (*sql.Stmt, error)
+}
+
+// querier returns the transaction with the id 'tid', or the database if no id is provided
+func (l *localDA) querier(tid *string) (querier, error) {
+	if aws.StringValue(tid) == "" {
+		return l.db, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tx, ok := l.txs[*tid]
+	if !ok {
+		return nil, notFound(*tid)
+	}
+
+	return tx, nil
+}
+
+// ExecuteStatementWithContext implements DA
+func (l *localDA) ExecuteStatementWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.ExecuteStatementInput,
+	opts ...request.Option) (*rdsdataservice.ExecuteStatementOutput, error) {
+	qr, err := l.querier(in.TransactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	q, names := l.rewrite(aws.StringValue(in.Sql))
+	args, err := localArgs(names, in.Parameters)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	out := &rdsdataservice.ExecuteStatementOutput{}
+	if !isQuery(q) {
+		res, err := qr.ExecContext(ctx, q, args...)
+		if err != nil {
+			return nil, badRequest(err)
+		}
+
+		out.NumberOfRecordsUpdated, out.GeneratedFields = localResult(res)
+		return out, nil
+	}
+
+	rows, err := qr.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	defer rows.Close()
+
+	cts, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	if aws.BoolValue(in.IncludeResultMetadata) {
+		out.ColumnMetadata = localColumnMetadata(cts)
+	}
+
+	numeric := in.ResultSetOptions != nil && aws.StringValue(in.ResultSetOptions.DecimalReturnType) ==
+		rdsdataservice.DecimalReturnTypeDoubleOrLong
+
+	out.Records = [][]*rdsdataservice.Field{}
+	for rows.Next() {
+		vals := make([]interface{}, len(cts))
+		ptrs := make([]interface{}, len(cts))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+
+		if err = rows.Scan(ptrs...); err != nil {
+			return nil, badRequest(err)
+		}
+
+		rec := make([]*rdsdataservice.Field, len(vals))
+		for i, v := range vals {
+			if rec[i], err = localField(v, cts[i].DatabaseTypeName()); err != nil {
+				return nil, badRequest(err)
+			}
+
+			if numeric && columnKind(cts[i].DatabaseTypeName()) == kindDecimal {
+				if rec[i], err = numericField(rec[i]); err != nil {
+					return nil, badRequest(err)
+				}
+			}
+		}
+
+		out.Records = append(out.Records, rec)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, badRequest(err)
+	}
+
+	out.SetNumberOfRecordsUpdated(0)
+	return out, nil
+}
+
+// BeginTransactionWithContext implements DA. The transaction is not bound to the context since
+// it needs to outlive the call.
+func (l *localDA) BeginTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.BeginTransactionInput,
+	opts ...request.Option) (*rdsdataservice.BeginTransactionOutput, error) {
+	tx, err := l.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	id := make([]byte, 48)
+	if _, err = rand.Read(id); err != nil {
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("failed to generate transaction id: %w", err)
+	}
+
+	tid := base64.StdEncoding.EncodeToString(id)
+
+	l.mu.Lock()
+	l.txs[tid] = tx
+	l.mu.Unlock()
+
+	return (&rdsdataservice.BeginTransactionOutput{}).SetTransactionId(tid), nil
+}
+
+// CommitTransactionWithContext implements DA
+func (l *localDA) CommitTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.CommitTransactionInput,
+	opts ...request.Option) (*rdsdataservice.CommitTransactionOutput, error) {
+	tx, err := l.endTx(aws.StringValue(in.TransactionId))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return (&rdsdataservice.CommitTransactionOutput{}).
+		SetTransactionStatus("Transaction Committed"), nil
+}
+
+// RollbackTransactionWithContext implements DA
+func (l *localDA) RollbackTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.RollbackTransactionInput,
+	opts ...request.Option) (*rdsdataservice.RollbackTransactionOutput, error) {
+	tx, err := l.endTx(aws.StringValue(in.TransactionId))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Rollback(); err != nil {
+		return nil, badRequest(err)
+	}
+
+	return (&rdsdataservice.RollbackTransactionOutput{}).
+		SetTransactionStatus("Rollback Complete"), nil
+}
+
+// endTx removes the transaction from the ones that are being tracked and returns it
+func (l *localDA) endTx(tid string) (*sql.Tx, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tx, ok := l.txs[tid]
+	if !ok {
+		return nil, notFound(tid)
+	}
+
+	delete(l.txs, tid)
+	return tx, nil
+}
+
+// BatchExecuteStatementWithContext implements DA
+func (l *localDA) BatchExecuteStatementWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.BatchExecuteStatementInput,
+	opts ...request.Option) (*rdsdataservice.BatchExecuteStatementOutput, error) {
+	qr, err := l.querier(in.TransactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	q, names := l.rewrite(aws.StringValue(in.Sql))
+	stmt, err := qr.PrepareContext(ctx, q)
+	if err != nil {
+		return nil, badRequest(err)
+	}
+
+	defer stmt.Close()
+
+	out := &rdsdataservice.BatchExecuteStatementOutput{
+		UpdateResults: []*rdsdataservice.UpdateResult{}}
+	for _, params := range in.ParameterSets {
+		args, err := localArgs(names, params)
+		if err != nil {
+			return nil, badRequest(err)
+		}
+
+		res, err := stmt.ExecContext(ctx, args...)
+		if err != nil {
+			return nil, badRequest(err)
+		}
+
+		_, gen := localResult(res)
+		out.UpdateResults = append(out.UpdateResults,
+			&rdsdataservice.UpdateResult{GeneratedFields: gen})
+	}
+
+	return out, nil
+}
+
+// rewrite rewrites the named placeholders for the dialect of the local database, if it has one
+func (l *localDA) rewrite(q string) (string, []string) {
+	if l.dialect == DialectNone {
+		return q, nil
+	}
+
+	return rewriteNamed(q, l.dialect)
+}
+
+// localArgs converts the Data API parameters into arguments for the local database
+func localArgs(names []string, params []*rdsdataservice.SqlParameter) ([]interface{}, error) {
+	args := make([]interface{}, len(params))
+	for i, p := range params {
+		v, err := fieldValue(p.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		args[i] = sql.Named(aws.StringValue(p.Name), v)
+	}
+
+	return bindNamed(names, args)
+}
+
+// localResult turns the result of an exec into the nr of updated records and generated fields
+func localResult(res sql.Result) (n *int64, gen []*rdsdataservice.Field) {
+	gen = []*rdsdataservice.Field{}
+	if id, err := res.LastInsertId(); err == nil && id > 0 {
+		gen = append(gen, &rdsdataservice.Field{LongValue: aws.Int64(id)})
+	}
+
+	ra, _ := res.RowsAffected()
+	return aws.Int64(ra), gen
+}
+
+// localColumnMetadata turns the column types of the local database into Data API metadata
+func localColumnMetadata(cts []*sql.ColumnType) []*rdsdataservice.ColumnMetadata {
+	cms := make([]*rdsdataservice.ColumnMetadata, len(cts))
+	for i, ct := range cts {
+		cm := (&rdsdataservice.ColumnMetadata{}).
+			SetName(ct.Name()).
+			SetLabel(ct.Name()).
+			SetTypeName(ct.DatabaseTypeName()).
+			SetNullable(2) // unknown
+
+		if nullable, ok := ct.Nullable(); ok && nullable {
+			cm.SetNullable(1)
+		} else if ok {
+			cm.SetNullable(0)
+		}
+
+		if prec, scale, ok := ct.DecimalSize(); ok {
+			cm.SetPrecision(prec).SetScale(scale)
+		} else if l, ok := ct.Length(); ok {
+			cm.SetPrecision(l)
+		}
+
+		switch columnKind(ct.DatabaseTypeName()) {
+		case kindLong, kindDouble, kindDecimal:
+			cm.SetIsSigned(!strings.Contains(strings.ToUpper(ct.DatabaseTypeName()), "UNSIGNED"))
+		}
+
+		cms[i] = cm
+	}
+
+	return cms
+}
+
+// colKind classifies database column types by the kind of field the Data API returns for them
+type colKind int
+
+const (
+	kindUnknown colKind = iota
+	kindString
+	kindLong
+	kindDouble
+	kindDecimal
+	kindBool
+	kindBlob
+	kindDate
+	kindTime
+	kindTimestamp
+)
+
+// columnKind classifies a database type name by the kind of field the Data API returns for it
+func columnKind(typeName string) colKind {
+	tn := strings.ToUpper(strings.TrimSpace(typeName))
+	tn = strings.TrimSpace(strings.TrimPrefix(strings.TrimSuffix(tn, " UNSIGNED"), "UNSIGNED "))
+	switch tn {
+	case "":
+		return kindUnknown
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "INT2", "INT4",
+		"INT8", "SERIAL", "SERIAL4", "SERIAL8", "BIGSERIAL", "SMALLSERIAL", "YEAR":
+		return kindLong
+	case "FLOAT", "DOUBLE", "REAL", "FLOAT4", "FLOAT8", "DOUBLE PRECISION":
+		return kindDouble
+	case "DECIMAL", "NUMERIC":
+		return kindDecimal
+	case "BOOL", "BOOLEAN":
+		return kindBool
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY", "BYTEA", "BIT",
+		"GEOMETRY":
+		return kindBlob
+	case "DATE":
+		return kindDate
+	case "TIME", "TIMETZ":
+		return kindTime
+	case "DATETIME", "TIMESTAMP", "TIMESTAMPTZ":
+		return kindTimestamp
+	default:
+		return kindString
+	}
+}
+
+// localField turns a value as scanned from the local database into a Data API field. Drivers
+// often return text for all column types, so the column type is used to decide on the field.
+func localField(v interface{}, typeName string) (*rdsdataservice.Field, error) {
+	f, kind := &rdsdataservice.Field{}, columnKind(typeName)
+	switch vt := v.(type) {
+	case nil:
+		f.IsNull = aws.Bool(true)
+	case []byte:
+		switch kind {
+		case kindUnknown, kindBlob:
+			f.BlobValue = cloneBytes(vt)
+		default:
+			return localField(string(vt), typeName)
+		}
+	case string:
+		switch kind {
+		case kindLong:
+			n, err := strconv.ParseInt(vt, 10, 64)
+			if err != nil {
+				if _, uerr := strconv.ParseUint(vt, 10, 64); uerr != nil {
+					return nil, err
+				}
+				f.StringValue = aws.String(vt) // unsigned that doesn't fit a long
+				break
+			}
+			f.LongValue = aws.Int64(n)
+		case kindDouble:
+			n, err := strconv.ParseFloat(vt, 64)
+			if err != nil {
+				return nil, err
+			}
+			f.DoubleValue = aws.Float64(n)
+		case kindBool:
+			b, err := strconv.ParseBool(vt)
+			if err != nil {
+				return nil, err
+			}
+			f.BooleanValue = aws.Bool(b)
+		default:
+			f.StringValue = aws.String(vt)
+		}
+	case bool:
+		f.BooleanValue = aws.Bool(vt)
+	case time.Time:
+		switch kind {
+		case kindDate:
+			f.StringValue = aws.String(vt.Format(dateLayout))
+		case kindTime:
+			f.StringValue = aws.String(vt.Format(timeLayout))
+		default:
+			if strings.EqualFold(strings.TrimSpace(typeName), "TIMESTAMPTZ") {
+				vt = vt.UTC() // the Data API returns timestamps with a zone in UTC
+			}
+			f.StringValue = aws.String(vt.Format(timestampLayout))
+		}
+	default:
+		switch rv := reflect.ValueOf(v); rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f.LongValue = aws.Int64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if rv.Uint() > math.MaxInt64 {
+				f.StringValue = aws.String(strconv.FormatUint(rv.Uint(), 10)) // doesn't fit a long
+			} else {
+				f.LongValue = aws.Int64(int64(rv.Uint()))
+			}
+		case reflect.Float32, reflect.Float64:
+			f.DoubleValue = aws.Float64(rv.Float())
+		default:
+			return nil, fmt.Errorf("unsupported value of type %T", v)
+		}
+	}
+
+	return f, nil
+}
+
+// numericField turns a decimal string field into a long or double field, like the Data API does
+// when the decimal return type is DOUBLE_OR_LONG.
+func numericField(f *rdsdataservice.Field) (*rdsdataservice.Field, error) {
+	if f.StringValue == nil {
+		return f, nil
+	}
+
+	if n, err := strconv.ParseInt(*f.StringValue, 10, 64); err == nil {
+		return &rdsdataservice.Field{LongValue: aws.Int64(n)}, nil
+	}
+
+	d, err := strconv.ParseFloat(*f.StringValue, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rdsdataservice.Field{DoubleValue: aws.Float64(d)}, nil
+}
+
+// isQuery returns whether the sql is expected to return records, based on the first keyword
+func isQuery(q string) bool {
+	for i := 0; i < len(q); i++ {
+		switch c := q[i]; {
+		case c == ' ', c == '\t', c == '\n', c == '\r', c == '(':
+		case c == '-' && strings.HasPrefix(q[i:], "--"), c == '#':
+			i = skipLine(q, i)
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			i = skipBlockComment(q, i, true)
+		default:
+			fields := strings.FieldsFunc(strings.ToUpper(q[i:]), func(r rune) bool {
+				return r != '_' && (r < 'A' || r > 'Z')
+			})
+
+			if len(fields) < 1 {
+				return false
+			}
+
+			switch fields[0] {
+			case "SELECT", "WITH", "SHOW", "VALUES", "EXPLAIN", "DESCRIBE", "DESC", "TABLE", "FETCH":
+				return true
+			}
+
+			return indexOf(fields, "RETURNING") >= 0
+		}
+	}
+
+	return false
+}
+
+// badRequest turns an error into the exception that the Data API uses for failing statements
+func badRequest(err error) error {
+	return &rdsdataservice.BadRequestException{Message_: aws.String(err.Error())}
+}
+
+// notFound returns the exception the Data API uses for transactions that don't exist
+func notFound(tid string) error {
+	return &rdsdataservice.NotFoundException{
+		Message_: aws.String(fmt.Sprintf("Transaction %s is not found", tid))}
+}
@@ -0,0 +1,71 @@
+// +build sqlite
+
+package dasql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestLocalDASQLite runs the emulation against a real database/sql driver instead of the stub
+func TestLocalDASQLite(t *testing.T) {
+	sdb, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	defer sdb.Close()
+	sdb.SetMaxOpenConns(1) // every connection has its own in-memory database
+
+	db, ctx := New(LocalDA(sdb, DialectMySQL), "", ""), context.Background()
+	if _, err = db.Exec(ctx, `CREATE TABLE foo (id INTEGER PRIMARY KEY, name TEXT, price REAL, data BLOB)`); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	res, err := db.Exec(ctx, `INSERT INTO foo (name, price, data) VALUES (:name, :price, :data)`,
+		sql.Named("name", "bar"), sql.Named("price", 1.5), sql.Named("data", []byte{0x01}))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if id, _ := res.LastInsertId(); id != 1 {
+		t.Fatalf("got: %v", id)
+	}
+
+	tx, err := db.Tx(ctx)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE foo SET name = :name WHERE id = :id`,
+		sql.Named("name", "baz"), sql.Named("id", 1)); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	rows, err := db.Query(ctx, `SELECT id, name, price, data FROM foo WHERE name = :name`,
+		sql.Named("name", "baz"))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var id int64
+	var name string
+	var price float64
+	var data []byte
+	for rows.Next() {
+		if err = rows.Scan(&id, &name, &price, &data); err != nil {
+			t.Fatalf("got: %v", err)
+		}
+	}
+
+	if id != 1 || name != "baz" || price != 1.5 || len(data) != 1 || data[0] != 0x01 {
+		t.Fatalf("got: %v %v %v %v", id, name, price, data)
+	}
+}
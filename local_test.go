@@ -0,0 +1,234 @@
+package dasql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// newLocalStub returns a local DA that is backed by a database that uses the stub Data API
+// through this package's driver. It allows testing the emulation without a real database.
+func newLocalStub(d Dialect) (*stubDA, DA) {
+	da := &stubDA{}
+	return da, LocalDA(sql.OpenDB(NewConnector(New(da, "", "", WithDialect(d)))), d)
+}
+
+func TestLocalDAQuery(t *testing.T) {
+	stub, lda := newLocalStub(DialectMySQL)
+	stub.nextESO = &rdsdataservice.ExecuteStatementOutput{Records: [][]*rdsdataservice.Field{
+		{{StringValue: aws.String("foo")}, {LongValue: aws.Int64(1)}, {IsNull: aws.Bool(true)}},
+	}}
+
+	db, ctx := New(lda, "", ""), context.Background()
+	rows, err := db.Query(ctx, `SELECT a, b, c FROM foo WHERE a = :a AND b = :b OR a = :a`,
+		sql.Named("a", "foo"), sql.Named("b", 1))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(stub.lastESI.Sql); act !=
+		`SELECT a, b, c FROM foo WHERE a = :p1 AND b = :p2 OR a = :p3` {
+		t.Fatalf("got: %v", act)
+	}
+
+	if len(stub.lastESI.Parameters) != 3 ||
+		aws.StringValue(stub.lastESI.Parameters[2].Value.StringValue) != "foo" {
+		t.Fatalf("got: %v", stub.lastESI.Parameters)
+	}
+
+	var a string
+	var b int64
+	var c sql.NullString
+	for rows.Next() {
+		if err = rows.Scan(&a, &b, &c); err != nil {
+			t.Fatalf("got: %v", err)
+		}
+	}
+
+	if a != "foo" || b != 1 || c.Valid {
+		t.Fatalf("got: %v %v %v", a, b, c)
+	}
+}
+
+func TestLocalDAExec(t *testing.T) {
+	stub, lda := newLocalStub(DialectPostgres)
+	stub.nextESO = &rdsdataservice.ExecuteStatementOutput{
+		NumberOfRecordsUpdated: aws.Int64(1),
+		GeneratedFields:        []*rdsdataservice.Field{{LongValue: aws.Int64(42)}},
+	}
+
+	db, ctx := New(lda, "", ""), context.Background()
+	res, err := db.Exec(ctx, `-- insert
+		INSERT INTO foo (a) VALUES (:a)`, sql.Named("a", []byte{0x01}))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if id, _ := res.LastInsertId(); id != 42 {
+		t.Fatalf("got: %v", id)
+	}
+
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Fatalf("got: %v", n)
+	}
+
+	if len(stub.lastESI.Parameters) != 1 || stub.lastESI.Parameters[0].Value.BlobValue == nil {
+		t.Fatalf("got: %v", stub.lastESI.Parameters)
+	}
+
+	stub.nextESOE = errors.New("syntax error")
+	_, err = db.Exec(ctx, `INSERT INTO foo`)
+
+	var brerr *rdsdataservice.BadRequestException
+	if !errors.As(err, &brerr) || !strings.Contains(brerr.Message(), "syntax error") {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestLocalDATx(t *testing.T) {
+	stub, lda := newLocalStub(DialectMySQL)
+	stub.nextBTO = &rdsdataservice.BeginTransactionOutput{TransactionId: aws.String("1234")}
+	stub.nextESO = &rdsdataservice.ExecuteStatementOutput{}
+
+	db, ctx := New(lda, "", ""), context.Background()
+	tx, err := db.Tx(ctx)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM foo`); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(stub.lastESI.TransactionId); act != "1234" {
+		t.Fatalf("got: %v", act)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(stub.lastCTI.TransactionId); act != "1234" {
+		t.Fatalf("got: %v", act)
+	}
+
+	if err = tx.Rollback(); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("got: %v", err)
+	}
+
+	var nferr *rdsdataservice.NotFoundException
+	if _, err = lda.ExecuteStatementWithContext(ctx, (&rdsdataservice.ExecuteStatementInput{}).
+		SetSql(`DELETE FROM foo`).SetTransactionId("1234")); !errors.As(err, &nferr) {
+		t.Fatalf("got: %v", err)
+	}
+
+	if tx, err = db.Tx(ctx); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = tx.Rollback(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(stub.lastRTI.TransactionId); act != "1234" {
+		t.Fatalf("got: %v", act)
+	}
+}
+
+func TestLocalDABatch(t *testing.T) {
+	stub, lda := newLocalStub(DialectMySQL)
+	stub.nextESO = &rdsdataservice.ExecuteStatementOutput{
+		GeneratedFields: []*rdsdataservice.Field{{LongValue: aws.Int64(42)}}}
+
+	db, ctx := New(lda, "", ""), context.Background()
+	res, err := db.ExecBatch(ctx, NewBatch(`INSERT INTO foo (a) VALUES (:a)`).
+		Exec(sql.Named("a", 1)).
+		Exec(sql.Named("a", 2)))
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if len(res) != 2 {
+		t.Fatalf("got: %v", res)
+	}
+
+	if id, _ := res[1].LastInsertId(); id != 42 {
+		t.Fatalf("got: %v", id)
+	}
+
+	if act := aws.Int64Value(stub.lastESI.Parameters[0].Value.LongValue); act != 2 {
+		t.Fatalf("got: %v", act)
+	}
+}
+
+func TestLocalField(t *testing.T) {
+	ts := time.Date(2020, 11, 17, 10, 30, 1, 5e8, time.UTC)
+	for i, c := range []struct {
+		v        interface{}
+		typeName string
+		exp      string
+	}{
+		{nil, "INT", `{IsNull:true}`},
+		{[]byte("12"), "BIGINT UNSIGNED", `{LongValue:12}`},
+		{[]byte("1.5"), "DOUBLE", `{DoubleValue:1.5}`},
+		{[]byte("12.30"), "DECIMAL", `{StringValue:"12.30"}`},
+		{[]byte("true"), "BOOL", `{BooleanValue:true}`},
+		{[]byte("foo"), "VARCHAR", `{StringValue:"foo"}`},
+		{[]byte{0x01}, "BLOB", `{BlobValue:<binary>len1}`},
+		{[]byte{0x01}, "", `{BlobValue:<binary>len1}`},
+		{int32(3), "INT4", `{LongValue:3}`},
+		{uint8(3), "TINYINT", `{LongValue:3}`},
+		{uint64(math.MaxUint64), "BIGINT UNSIGNED", `{StringValue:"18446744073709551615"}`},
+		{[]byte("18446744073709551615"), "BIGINT UNSIGNED", `{StringValue:"18446744073709551615"}`},
+		{float32(0.5), "FLOAT4", `{DoubleValue:0.5}`},
+		{true, "BOOLEAN", `{BooleanValue:true}`},
+		{ts, "DATETIME", `{StringValue:"2020-11-1710:30:01.5"}`},
+		{ts, "DATE", `{StringValue:"2020-11-17"}`},
+		{ts, "TIME", `{StringValue:"10:30:01.5"}`},
+		{ts.In(time.FixedZone("", 3600)), "timestamptz", `{StringValue:"2020-11-1710:30:01.5"}`},
+		{ts.In(time.FixedZone("", 3600)), "TIMESTAMP", `{StringValue:"2020-11-1711:30:01.5"}`},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			f, err := localField(c.v, c.typeName)
+			if err != nil {
+				t.Fatalf("got: %v", err)
+			}
+
+			if act := strings.Join(strings.Fields(f.String()), ""); act != c.exp {
+				t.Fatalf("exp: %v got: %v", c.exp, act)
+			}
+		})
+	}
+
+	if _, err := localField([]byte("foo"), "INT"); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err := localField(struct{}{}, "INT"); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestIsQuery(t *testing.T) {
+	for q, exp := range map[string]bool{
+		`SELECT 1`:                      true,
+		"  (select 1) UNION (select 2)": true,
+		"-- comment\n/* SELECT */ with a as (select 1) select * from a": true,
+		`INSERT INTO foo (a) VALUES (1) RETURNING id`:                   true,
+		`INSERT INTO foo (a) VALUES (1)`:                                false,
+		`UPDATE foo SET a = 1`:                                          false,
+		`-- only a comment`:                                             false,
+	} {
+		if act := isQuery(q); act != exp {
+			t.Fatalf("exp: %v got: %v for %q", exp, act, q)
+		}
+	}
+}
@@ -13,7 +13,8 @@ function print_help {
 
 function run_test { # test the complete codebase and show coverage report
 	command -v go >/dev/null 2>&1 || { echo "executable 'go' must be installed" >&2; exit 1; }	
-	go test -race -covermode=atomic -coverprofile=/tmp/cover ./... \
+	go test -race -tags sqlite -covermode=atomic -coverprofile=/tmp/cover ./... \
+		&& (cd cmd/dasql-local && go test -race ./...) \
 		&& go tool cover -html=/tmp/cover 
 }
 
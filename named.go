@@ -0,0 +1,116 @@
+package dasql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// NamedFromArg is an argument that provides named parameters from the fields of a struct or the
+// entries of a map, see NamedFrom.
+type NamedFromArg struct{ src interface{} }
+
+// NamedFrom returns an argument that expands into a sql.NamedArg for each named placeholder in the
+// query that has a matching field or map entry in 'src'. Struct fields are matched by their 'db'
+// tag or their mapped name, just like ScanStruct, fields with the 'json' tag option are sent as
+// JSON. Explicit sql.NamedArg arguments take precedence, and fields without a placeholder in the
+// query are ignored.
+func NamedFrom(src interface{}) NamedFromArg { return NamedFromArg{src} }
+
+// value returns the struct or map the arguments are taken from
+func (a NamedFromArg) value() (reflect.Value, error) {
+	rv := reflect.ValueOf(a.src)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct &&
+		(rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String) {
+		return rv, ArgErr{Kind: ArgErrKindUnsupported, Type: fmt.Sprintf("%T", a.src)}
+	}
+
+	return rv, nil
+}
+
+// lookup returns the value of the field or map entry with the name 'name'
+func (a NamedFromArg) lookup(m *structMapper, name string) (interface{}, bool) {
+	rv, _ := a.value()
+	if rv.Kind() == reflect.Map {
+		v := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+		if !v.IsValid() {
+			return nil, false
+		}
+
+		return v.Interface(), true
+	}
+
+	sf, ok := m.fields(rv.Type())[name]
+	if !ok {
+		return nil, false
+	}
+
+	for i, x := range sf.index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return nil, false // embedded through a nil pointer
+			}
+
+			rv = rv.Elem()
+		}
+
+		rv = rv.Field(x)
+	}
+
+	if sf.json {
+		return JSON(rv.Interface()), true
+	}
+
+	return rv.Interface(), true
+}
+
+// expandNamed replaces the NamedFrom arguments with a named argument for each named placeholder
+// in 'q' that they provide a value for. Explicit named arguments take precedence.
+func expandNamed(q string, d Dialect, m *structMapper, args []interface{}) ([]interface{}, error) {
+	var srcs []NamedFromArg
+	explicit := map[string]bool{}
+	nargs := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		switch at := arg.(type) {
+		case NamedFromArg:
+			if _, err := at.value(); err != nil {
+				return nil, err
+			}
+
+			srcs = append(srcs, at)
+			continue
+		case sql.NamedArg:
+			explicit[at.Name] = true
+		}
+
+		nargs = append(nargs, arg)
+	}
+
+	if len(srcs) == 0 {
+		return args, nil
+	}
+
+	if m == nil {
+		m = defaultMapper
+	}
+
+	for _, ph := range scanPlaceholders(q, d) {
+		if ph.kind != placeholderNamed || explicit[ph.name] {
+			continue
+		}
+
+		explicit[ph.name] = true
+		for _, src := range srcs {
+			if v, ok := src.lookup(m, ph.name); ok {
+				nargs = append(nargs, sql.Named(ph.name, v))
+				break
+			}
+		}
+	}
+
+	return nargs, nil
+}
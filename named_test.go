@@ -0,0 +1,123 @@
+package dasql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestExpandNamed(t *testing.T) {
+	type user struct {
+		ID    int64  `db:"id"`
+		Email string `db:"email"`
+		Name  string
+	}
+
+	u := user{1, "foo@x.y", "foo"}
+	for i, c := range []struct {
+		q    string
+		args []interface{}
+		exp  string
+		err  error
+	}{
+		{`SELECT :id`, []interface{}{sql.Named("id", 2)}, `id=2`, nil},
+		{`SELECT :id, :name`, []interface{}{NamedFrom(u)}, `id=1 name=foo`, nil},
+		{`SELECT :email`, []interface{}{NamedFrom(&u)}, `email=foo@x.y`, nil},
+		{`SELECT :id, :name`, []interface{}{NamedFrom(u), sql.Named("id", 2)},
+			`id=2 name=foo`, nil},
+		{`SELECT :id, :id, ':email'`, []interface{}{NamedFrom(u)}, `id=1`, nil},
+		{`SELECT :id, :bar`, []interface{}{NamedFrom(map[string]interface{}{"bar": 1}), NamedFrom(u)},
+			`id=1 bar=1`, nil},
+		{`SELECT :id, :bar`, []interface{}{NamedFrom(u)}, `id=1`, nil},
+		{`SELECT :id`, []interface{}{NamedFrom(1)}, ``,
+			ArgErr{Kind: ArgErrKindUnsupported, Type: "int"}},
+		{`SELECT :id`, []interface{}{NamedFrom(map[int]string{})}, ``,
+			ArgErr{Kind: ArgErrKindUnsupported, Type: "map[int]string"}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			args, err := expandNamed(c.q, DialectNone, nil, c.args)
+			if c.err != nil {
+				if !errors.Is(err, c.err) {
+					t.Fatalf("exp: %v got: %v", c.err, err)
+				}
+
+				return
+			}
+
+			var act []string
+			for _, arg := range args {
+				act = append(act, fmt.Sprintf("%s=%v", arg.(sql.NamedArg).Name, arg.(sql.NamedArg).Value))
+			}
+
+			if s := strings.Join(act, " "); err != nil || s != c.exp {
+				t.Fatalf("exp: %v got: %v (%v)", c.exp, s, err)
+			}
+		})
+	}
+}
+
+func TestDBNamedFrom(t *testing.T) {
+	u := struct {
+		ID   int64 `db:"id"`
+		Name string
+	}{1, "foo"}
+
+	da, ctx := &stubDA{
+		nextESO:  &rdsdataservice.ExecuteStatementOutput{},
+		nextBESO: &rdsdataservice.BatchExecuteStatementOutput{},
+	}, context.Background()
+
+	db := New(da, "", "")
+	if _, err := db.Exec(ctx, `UPDATE users SET name = :name WHERE id = :id`, NamedFrom(u)); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := da.lastESI.Parameters; len(act) != 2 || aws.StringValue(act[0].Name) != "name" ||
+		aws.Int64Value(act[1].Value.LongValue) != 1 {
+		t.Fatalf("got: %v", act)
+	}
+
+	if _, err := db.ExecBatch(ctx, NewBatch(`DELETE FROM users WHERE id = :id`).
+		Exec(NamedFrom(u)).
+		Exec(NamedFrom(map[string]int64{"id": 2}))); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := da.lastBESI.ParameterSets; len(act) != 2 || len(act[0]) != 1 ||
+		aws.Int64Value(act[1][0].Value.LongValue) != 2 {
+		t.Fatalf("got: %v", act)
+	}
+
+	// through the adapted database, driver and back into the DB
+	sdb := Adapt(sql.OpenDB(NewConnector(db)))
+	if _, err := sdb.Exec(ctx, `DELETE FROM users WHERE id = :id`, NamedFrom(u)); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := da.lastESI.Parameters; len(act) != 1 || aws.StringValue(act[0].Name) != "id" {
+		t.Fatalf("got: %v", act)
+	}
+
+	// json fields through an adapted database of another driver
+	row := struct {
+		ID   int64             `db:"id"`
+		Tags map[string]string `db:"tags,json"`
+	}{1, map[string]string{"a": "b"}}
+
+	conn := &argsConnector{}
+	if _, err := Adapt(sql.OpenDB(conn)).Exec(ctx,
+		`UPDATE users SET tags = :tags WHERE id = :id`, NamedFrom(row)); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := conn.args; len(act) != 2 || act[0].Name != "tags" || act[0].Value != `{"a":"b"}` {
+		t.Fatalf("got: %v", act)
+	}
+}
@@ -0,0 +1,173 @@
+package dasql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// isSizeLimitErr returns whether the Data API failed the statement because its response would be
+// larger than the maximum response size (1MB).
+func isSizeLimitErr(err error) bool {
+	var brerr *rdsdataservice.BadRequestException
+	return errors.As(err, &brerr) && strings.Contains(brerr.Message(), "response size limit")
+}
+
+// trimStatement removes the trailing white space and semicolon of the statement, so it can be
+// embedded in another statement.
+func trimStatement(q string) string {
+	return strings.TrimRight(strings.TrimSpace(q), ";")
+}
+
+// pageQuery appends the LIMIT and OFFSET clause for a page to the query. A newline is used so a
+// trailing line comment doesn't comment out the clause.
+func pageQuery(q string, limit, offset int) string {
+	return fmt.Sprintf("%s\nLIMIT %d OFFSET %d", trimStatement(q), limit, offset)
+}
+
+// pagingClause returns the clause of the query that conflicts with the LIMIT and OFFSET that are
+// appended for paging: a LIMIT, OFFSET or FETCH clause of its own, or a locking clause which must
+// come after the LIMIT. Only words outside of parentheses, quoted text and comments are considered.
+func pagingClause(q string, d Dialect) string {
+	var depth int
+	var prev string
+	for i := 0; i < len(q); i++ {
+		switch c := q[i]; {
+		case c == '\'':
+			i = skipQuoted(q, i, '\'', d == DialectMySQL || isEscapeString(q, i, d))
+		case c == '"':
+			i = skipQuoted(q, i, '"', d == DialectMySQL)
+		case c == '`':
+			i = skipQuoted(q, i, '`', false)
+		case c == '-' && strings.HasPrefix(q[i:], "--"),
+			c == '#' && d == DialectMySQL:
+			i = skipLine(q, i)
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			i = skipBlockComment(q, i, d == DialectPostgres)
+		case c == '$' && d != DialectMySQL && (i == 0 || !isIdentChar(q[i-1])):
+			i = skipDollarQuoted(q, i)
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case isIdentStart(c) && (i == 0 || !isIdentChar(q[i-1])):
+			end := i + 1
+			for end < len(q) && isIdentChar(q[end]) {
+				end++
+			}
+
+			w := strings.ToUpper(q[i:end])
+			if depth > 0 || (i > 0 && (q[i-1] == ':' || q[i-1] == '.')) {
+				i = end - 1
+				continue // a placeholder, a qualified name or a nested query
+			}
+
+			switch {
+			case w == "LIMIT" || w == "OFFSET" || w == "FETCH":
+				return w
+			case prev == "FOR" && (w == "UPDATE" || w == "SHARE"):
+				return "FOR " + w
+			case prev == "FOR" && w == "NO":
+				return "FOR NO KEY UPDATE"
+			case prev == "FOR" && w == "KEY":
+				return "FOR KEY SHARE"
+			case prev == "LOCK" && w == "IN":
+				return "LOCK IN SHARE MODE"
+			}
+
+			prev, i = w, end-1
+		}
+	}
+
+	return ""
+}
+
+// QueryPaged executes a query that may return more data than the Data API allows in a single
+// response. The query is executed in pages of 'pageSize' rows by appending a LIMIT and OFFSET
+// clause, so it should have an ORDER BY that is deterministic. A query with a LIMIT, OFFSET or
+// FETCH clause of its own, or a locking clause such as FOR UPDATE, is rejected. The next page is
+// fetched when Next is called on the last row of a page, errors that happen while doing so are
+// returned by the Err method of the rows. If a page is still too large the page size is halved
+// until it fits.
+func (db *DB) QueryPaged(ctx context.Context, q string, pageSize int, args ...interface{}) (Rows, error) {
+	if pageSize < 1 {
+		return nil, fmt.Errorf("dasql: page size must be larger than zero, got: %d", pageSize)
+	}
+
+	if clause := pagingClause(q, db.dialect); clause != "" {
+		return nil, fmt.Errorf("dasql: a paged query can't have a %s clause, LIMIT and OFFSET are "+
+			"appended for each page", clause)
+	}
+
+	r := &pagedRows{ctx: ctx, db: db, q: q, args: args, size: pageSize}
+	if err := r.fetch(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// pagedRows implements the Rows interface by fetching pages of the result when needed
+type pagedRows struct {
+	*daRows
+
+	ctx  context.Context
+	db   *DB
+	q    string
+	args []interface{}
+
+	size int  // nr of rows per page, lowered when a page is too large
+	done bool // the last page has been fetched
+	err  error
+}
+
+// fetch replaces the current page with the next one
+func (r *pagedRows) fetch() error {
+	var off int
+	if r.daRows != nil {
+		off = r.off + len(r.recs)
+	}
+
+	for {
+		out, err := r.db.execStatement(r.ctx, "", true, pageQuery(r.q, r.size, off), r.args...)
+		if isSizeLimitErr(err) && r.size > 1 {
+			r.size /= 2
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		r.done = len(out.Records) < r.size
+		r.daRows = &daRows{out.Records, -1, out.ColumnMetadata, r.db.mapper, r.db.codec, off}
+		return nil
+	}
+}
+
+// Next prepares the next row for scanning, it fetches the next page if needed
+func (r *pagedRows) Next() bool {
+	if r.daRows.Next() {
+		return true
+	}
+
+	if r.done || r.err != nil {
+		return false
+	}
+
+	if r.err = r.fetch(); r.err != nil {
+		return false
+	}
+
+	return r.daRows.Next()
+}
+
+// Err returns the error that was encountered while fetching a page, if any
+func (r *pagedRows) Err() error { return r.err }
+
+// Close stops the fetching of pages
+func (r *pagedRows) Close() error {
+	r.done, r.recs = true, nil
+	return nil
+}
@@ -0,0 +1,163 @@
+package dasql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// pagedDA is a stub that pages through 'n' rows, responses with more than 'max' rows fail with the
+// response size limit error.
+type pagedDA struct {
+	stubDA
+	n, max int
+	sqls   []string
+	err    error
+}
+
+func (da *pagedDA) ExecuteStatementWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.ExecuteStatementInput,
+	opts ...request.Option) (*rdsdataservice.ExecuteStatementOutput, error) {
+	da.sqls = append(da.sqls, aws.StringValue(in.Sql))
+	if da.err != nil {
+		return nil, da.err
+	}
+
+	var limit, offset int
+	q := aws.StringValue(in.Sql)
+	if _, err := fmt.Sscanf(q[strings.LastIndex(q, "\n")+1:], "LIMIT %d OFFSET %d", &limit, &offset); err != nil {
+		return nil, err
+	}
+
+	if limit > da.max {
+		return nil, &rdsdataservice.BadRequestException{Message_: aws.String(
+			"Database returned more than the allowed response size limit")}
+	}
+
+	out := &rdsdataservice.ExecuteStatementOutput{
+		ColumnMetadata: []*rdsdataservice.ColumnMetadata{{Name: aws.String("id")}}}
+	for i := offset; i < offset+limit && i < da.n; i++ {
+		out.Records = append(out.Records, []*rdsdataservice.Field{{LongValue: aws.Int64(int64(i))}})
+	}
+
+	return out, nil
+}
+
+func TestQueryPaged(t *testing.T) {
+	da, ctx := &pagedDA{n: 7, max: 2}, context.Background()
+	db := New(da, "", "")
+
+	rows, err := db.QueryPaged(ctx, `SELECT id FROM foo ORDER BY id; `, 4)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			t.Fatalf("got: %v", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if RowsErr(rows) != nil || fmt.Sprint(ids) != "[0 1 2 3 4 5 6]" {
+		t.Fatalf("got: %v %v", ids, RowsErr(rows))
+	}
+
+	// the page size is halved once, the last page has less rows than the page size
+	exp := []string{
+		"SELECT id FROM foo ORDER BY id\nLIMIT 4 OFFSET 0",
+		"SELECT id FROM foo ORDER BY id\nLIMIT 2 OFFSET 0",
+		"SELECT id FROM foo ORDER BY id\nLIMIT 2 OFFSET 2",
+		"SELECT id FROM foo ORDER BY id\nLIMIT 2 OFFSET 4",
+		"SELECT id FROM foo ORDER BY id\nLIMIT 2 OFFSET 6",
+	}
+
+	if act := strings.Join(da.sqls, ","); act != strings.Join(exp, ",") {
+		t.Fatalf("got: %q", da.sqls)
+	}
+}
+
+func TestPagingClause(t *testing.T) {
+	for i, c := range []struct {
+		q   string
+		d   Dialect
+		exp string
+	}{
+		{`SELECT id FROM foo ORDER BY id`, DialectNone, ""},
+		{`SELECT id FROM foo WHERE id IN (SELECT id FROM bar LIMIT 1)`, DialectNone, ""},
+		{`SELECT id, 'limit', "offset", foo.limit FROM foo -- LIMIT 1`, DialectNone, ""},
+		{`SELECT id FROM foo WHERE a = :limit /* FOR UPDATE */`, DialectNone, ""},
+		{`SELECT id FROM foo FOR UPDATE`, DialectNone, "FOR UPDATE"},
+		{`SELECT id FROM foo limit 10`, DialectNone, "LIMIT"},
+		{`SELECT id FROM foo OFFSET 10 ROWS`, DialectPostgres, "OFFSET"},
+		{`SELECT id FROM foo FETCH FIRST 10 ROWS ONLY`, DialectPostgres, "FETCH"},
+		{`SELECT id FROM foo FOR NO KEY UPDATE`, DialectPostgres, "FOR NO KEY UPDATE"},
+		{`SELECT id FROM foo LOCK IN SHARE MODE`, DialectMySQL, "LOCK IN SHARE MODE"},
+	} {
+		if act := pagingClause(c.q, c.d); act != c.exp {
+			t.Fatalf("%d: exp: %q got: %q", i, c.exp, act)
+		}
+	}
+}
+
+func TestQueryPagedErrors(t *testing.T) {
+	da, ctx := &pagedDA{n: 3, max: 0}, context.Background()
+	db := New(da, "", "")
+
+	if _, err := db.QueryPaged(ctx, `SELECT id FROM foo`, 0); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err := db.QueryPaged(ctx, `SELECT id FROM foo FOR UPDATE`, 2); err == nil ||
+		!strings.Contains(err.Error(), "FOR UPDATE clause") || len(da.sqls) != 0 {
+		t.Fatalf("got: %v", err)
+	}
+
+	var brerr *rdsdataservice.BadRequestException
+	if _, err := db.QueryPaged(ctx, `SELECT id FROM foo`, 2); !errors.As(err, &brerr) {
+		t.Fatalf("got: %v", err)
+	}
+
+	da.max = 2
+	rows, err := db.QueryPaged(ctx, `SELECT id FROM foo`, 2)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	rows.Next()
+	rows.Next()
+
+	var se ScanErr
+	da.err = errors.New("connection lost")
+	if rows.Next() || !errors.Is(RowsErr(rows), da.err) {
+		t.Fatalf("got: %v", RowsErr(rows))
+	}
+
+	da.err = nil
+	if rows, err = db.QueryPaged(ctx, `SELECT id FROM foo`, 2); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	for rows.Next() {
+		var id bool
+		err = rows.Scan(&id)
+	}
+
+	if !errors.As(err, &se) || se.Row != 2 || se.Column != "id" {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = rows.Close(); err != nil || rows.Next() {
+		t.Fatalf("got: %v", err)
+	}
+}
@@ -0,0 +1,438 @@
+package dasql
+
+import (
+	"database/sql"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// Dialect identifies the SQL dialect of the database that is used
+type Dialect int
+
+const (
+	// DialectNone means that no dialect specific placeholders are used, only named ones
+	DialectNone Dialect = iota
+
+	// DialectMySQL uses '?' as positional placeholders
+	DialectMySQL
+
+	// DialectPostgres uses '$1' style positional placeholders
+	DialectPostgres
+)
+
+// placeholderKind describes the syntax of a placeholder
+type placeholderKind int
+
+const (
+	// placeholderNamed is a placeholder such as ':name'
+	placeholderNamed placeholderKind = iota
+
+	// placeholderQuestion is a positional placeholder written as '?'
+	placeholderQuestion
+
+	// placeholderDollar is a numbered positional placeholder written as '$1'
+	placeholderDollar
+)
+
+// placeholder is a parameter placeholder as it was found in sql text
+type placeholder struct {
+	kind       placeholderKind
+	start, end int    // byte offsets in the sql text
+	name       string // name without the colon, or the number of a dollar placeholder
+}
+
+// scanPlaceholders lexes the sql text and returns all the placeholders it contains. Placeholders
+// inside of string literals, quoted identifiers, comments and dollar-quoted strings are ignored.
+// Positional placeholders are only recognized for the dialect that uses them. Backslashes escape
+// the next character in quoted text unless the dialect is Postgres, which only allows that in
+// escape strings (E'...'). The Data API serves both, so that is assumed for DialectNone as well.
+func scanPlaceholders(q string, d Dialect) (phs []placeholder) {
+	for i := 0; i < len(q); i++ {
+		switch c := q[i]; {
+		case c == '\'':
+			i = skipQuoted(q, i, '\'', d != DialectPostgres || isEscapeString(q, i, d))
+		case c == '"':
+			i = skipQuoted(q, i, '"', d != DialectPostgres)
+		case c == '`':
+			i = skipQuoted(q, i, '`', false)
+		case c == '-' && strings.HasPrefix(q[i:], "--"),
+			c == '#' && d == DialectMySQL:
+			i = skipLine(q, i)
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			i = skipBlockComment(q, i, d == DialectPostgres)
+		case c == '?' && d == DialectMySQL:
+			phs = append(phs, placeholder{placeholderQuestion, i, i + 1, ""})
+		case c == '$' && d == DialectPostgres && i+1 < len(q) && isDigit(q[i+1]):
+			end := i + 1
+			for end < len(q) && isDigit(q[end]) {
+				end++
+			}
+
+			phs = append(phs, placeholder{placeholderDollar, i, end, q[i+1 : end]})
+			i = end - 1
+		case c == '$' && d != DialectMySQL && (i == 0 || !isIdentChar(q[i-1])):
+			i = skipDollarQuoted(q, i)
+		case c == ':' && i+1 < len(q) && isIdentStart(q[i+1]) && (i == 0 ||
+			(q[i-1] != ':' && !isIdentChar(q[i-1]))):
+			end := i + 1
+			for end < len(q) && isIdentChar(q[end]) && q[end] != '$' {
+				end++
+			}
+
+			phs = append(phs, placeholder{placeholderNamed, i, end, q[i+1 : end]})
+			i = end - 1
+		}
+	}
+
+	return
+}
+
+// skipQuoted returns the position of the quote that closes the quoted text starting at 'i'. A
+// double quote escapes itself and backslashes escape the next character if 'bs' is true.
+func skipQuoted(q string, i int, quote byte, bs bool) int {
+	for i++; i < len(q); i++ {
+		switch {
+		case bs && q[i] == '\\':
+			i++
+		case q[i] == quote && i+1 < len(q) && q[i+1] == quote:
+			i++
+		case q[i] == quote:
+			return i
+		}
+	}
+
+	return len(q)
+}
+
+// isEscapeString returns whether the string literal at 'i' is a Postgres escape string (E'...')
+// that allows for backslash escapes.
+func isEscapeString(q string, i int, d Dialect) bool {
+	return d == DialectPostgres && i > 0 && (q[i-1] == 'E' || q[i-1] == 'e') &&
+		(i == 1 || !isIdentChar(q[i-2]))
+}
+
+// skipLine returns the position of the end of the line that starts at 'i'
+func skipLine(q string, i int) int {
+	if n := strings.IndexByte(q[i:], '\n'); n >= 0 {
+		return i + n
+	}
+
+	return len(q)
+}
+
+// skipBlockComment returns the position of the last character of the comment starting at 'i'.
+// Postgres allows block comments to be nested.
+func skipBlockComment(q string, i int, nested bool) int {
+	depth := 0
+	for ; i < len(q); i++ {
+		switch {
+		case strings.HasPrefix(q[i:], "/*") && (nested || depth == 0):
+			depth++
+			i++
+		case strings.HasPrefix(q[i:], "*/"):
+			depth--
+			i++
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return len(q)
+}
+
+// skipDollarQuoted returns the position of the last character of the dollar-quoted string that
+// starts at 'i'. If there is no valid dollar quote tag at 'i' it is returned unchanged.
+func skipDollarQuoted(q string, i int) int {
+	end := i + 1
+	for end < len(q) && isIdentChar(q[end]) && q[end] != '$' {
+		end++
+	}
+
+	if end >= len(q) || q[end] != '$' || (end > i+1 && isDigit(q[i+1])) {
+		return i
+	}
+
+	tag := q[i : end+1]
+	if n := strings.Index(q[end+1:], tag); n >= 0 {
+		return end + n + len(tag)
+	}
+
+	return len(q)
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool { return isIdentStart(c) || isDigit(c) || c == '$' }
+
+// ParseNamed returns the names of the named placeholders (':name') in the sql text, in the order
+// of their first use. Placeholders inside of string literals, quoted identifiers, comments and
+// dollar-quoted strings are ignored, as are type casts such as '::int'.
+func ParseNamed(q string) []string {
+	return namedPlaceholders(q, DialectNone)
+}
+
+// namedPlaceholders returns the unique names of the named placeholders in the sql text
+func namedPlaceholders(q string, d Dialect) (names []string) {
+	for _, ph := range scanPlaceholders(q, d) {
+		if ph.kind == placeholderNamed && indexOf(names, ph.name) < 0 {
+			names = append(names, ph.name)
+		}
+	}
+
+	return
+}
+
+// isValidName returns whether 'name' can be used as a named placeholder
+func isValidName(name string) bool {
+	if name == "" || !isIdentStart(name[0]) {
+		return false
+	}
+
+	for i := 1; i < len(name); i++ {
+		if !isIdentChar(name[i]) || name[i] == '$' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateParams checks the parameters against the named placeholders of the query, so mistakes
+// are found before the query is sent to the Data API. Without a dialect, missing and unused
+// parameters are only reported if the placeholders are the same whether backslashes escape quotes
+// (MySQL) or not (Postgres), since the query can't be lexed with certainty otherwise.
+func validateParams(q string, d Dialect, params []*rdsdataservice.SqlParameter) error {
+	var invalid, dups []string
+	seen := make(map[string]bool, len(params))
+	for _, p := range params {
+		name := aws.StringValue(p.Name)
+		switch {
+		case !isValidName(name):
+			invalid = append(invalid, name)
+		case seen[name] && indexOf(dups, name) < 0:
+			dups = append(dups, name)
+		}
+
+		seen[name] = true
+	}
+
+	if len(invalid) > 0 {
+		return ArgErr{Kind: ArgErrKindInvalidName, Names: invalid}
+	}
+
+	if len(dups) > 0 {
+		return ArgErr{Kind: ArgErrKindDuplicate, Names: dups}
+	}
+
+	var missing, unused []string
+	names := namedPlaceholders(q, d)
+	if d == DialectNone && !reflect.DeepEqual(names, namedPlaceholders(q, DialectPostgres)) {
+		return nil
+	}
+	for _, name := range names {
+		if !seen[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return ArgErr{Kind: ArgErrKindMissing, Names: missing}
+	}
+
+	for _, p := range params {
+		if name := aws.StringValue(p.Name); indexOf(names, name) < 0 && indexOf(unused, name) < 0 {
+			unused = append(unused, name)
+		}
+	}
+
+	if len(unused) > 0 {
+		return ArgErr{Kind: ArgErrKindUnused, Names: unused}
+	}
+
+	return nil
+}
+
+// positionalName returns the parameter name that is generated for the positional argument at
+// (one-based) position 'n'.
+func positionalName(n int) string { return "p" + strconv.Itoa(n) }
+
+// rewritePositional rewrites the positional placeholders of dialect 'd' in the query into named
+// placeholders, and turns the positional args into named args to match: the first positional
+// argument is named 'p1', the second 'p2', etc. A named argument with one of those names is
+// rejected as a duplicate.
+func rewritePositional(
+	q string, d Dialect, args []interface{},
+) (string, []interface{}, error) {
+	if d == DialectNone {
+		return q, args, nil
+	}
+
+	var npos int
+	for _, arg := range args {
+		if _, ok := arg.(sql.NamedArg); !ok {
+			npos++
+		}
+	}
+
+	var b strings.Builder
+	var last, nph, max int
+	for _, ph := range scanPlaceholders(q, d) {
+		var n int
+		switch ph.kind {
+		case placeholderQuestion:
+			nph++
+			n = nph
+		case placeholderDollar:
+			n, _ = strconv.Atoi(ph.name)
+			if n < 1 || n > npos {
+				return "", nil, ArgErr{Kind: ArgErrKindPositional, Type: "$" + ph.name}
+			}
+		default:
+			continue
+		}
+
+		if n > max {
+			max = n
+		}
+
+		b.WriteString(q[last:ph.start])
+		b.WriteString(":" + positionalName(n))
+		last = ph.end
+	}
+
+	if max != npos {
+		return "", nil, ArgErr{Kind: ArgErrKindPositional}
+	}
+
+	if npos == 0 {
+		return q, args, nil
+	}
+
+	b.WriteString(q[last:])
+
+	var pos int
+	nargs := make([]interface{}, len(args))
+	for i, arg := range args {
+		if named, ok := arg.(sql.NamedArg); ok {
+			if n, err := strconv.Atoi(strings.TrimPrefix(named.Name, "p")); err == nil &&
+				positionalName(n) == named.Name && n >= 1 && n <= npos {
+				return "", nil, ArgErr{Kind: ArgErrKindDuplicate, Names: []string{named.Name}}
+			}
+
+			nargs[i] = arg
+			continue
+		}
+
+		pos++
+		nargs[i] = sql.Named(positionalName(pos), arg)
+	}
+
+	return b.String(), nargs, nil
+}
+
+// rewriteNamed rewrites the named placeholders in the query into positional placeholders of
+// dialect 'd'. It returns the rewritten query and the names of the arguments in positional order,
+// for MySQL a name is repeated for every use, for Postgres each name gets a single number.
+func rewriteNamed(q string, d Dialect) (string, []string) {
+	var b strings.Builder
+	var last int
+	var names []string
+	for _, ph := range scanPlaceholders(q, d) {
+		if ph.kind != placeholderNamed {
+			continue
+		}
+
+		b.WriteString(q[last:ph.start])
+		last = ph.end
+
+		if d != DialectPostgres {
+			b.WriteString("?")
+			names = append(names, ph.name)
+			continue
+		}
+
+		n := indexOf(names, ph.name)
+		if n < 0 {
+			names, n = append(names, ph.name), len(names)
+		}
+
+		b.WriteString("$" + strconv.Itoa(n+1))
+	}
+
+	if len(names) == 0 {
+		return q, nil
+	}
+
+	b.WriteString(q[last:])
+	return b.String(), names
+}
+
+// bindNamed returns the values of the named arguments in the order of 'names'. If there are no
+// names the arguments are returned as-is.
+func bindNamed(names []string, args []interface{}) ([]interface{}, error) {
+	if len(names) == 0 {
+		return args, nil
+	}
+
+	vals := make(map[string]interface{}, len(args))
+	for _, arg := range args {
+		named, ok := arg.(sql.NamedArg)
+		if !ok {
+			return nil, ArgErr{Kind: ArgErrKindPositional}
+		}
+
+		vals[named.Name] = named.Value
+	}
+
+	var missing []string
+	pargs := make([]interface{}, len(names))
+	for i, name := range names {
+		v, ok := vals[name]
+		if !ok && indexOf(missing, name) < 0 {
+			missing = append(missing, name)
+		}
+
+		pargs[i] = v
+	}
+
+	if len(missing) > 0 {
+		return nil, ArgErr{Kind: ArgErrKindMissing, Names: missing}
+	}
+
+	return pargs, nil
+}
+
+// rewriteNamedArgs rewrites the named placeholders in 'q' for dialect 'd' and binds the args
+func rewriteNamedArgs(q string, d Dialect, args []interface{}) (string, []interface{}, error) {
+	if d == DialectNone {
+		return q, args, nil
+	}
+
+	q, names := rewriteNamed(q, d)
+	args, err := bindNamed(names, args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return q, args, nil
+}
+
+// indexOf returns the index of 's' in 'ss' or -1 if it's not in there
+func indexOf(ss []string, s string) int {
+	for i, v := range ss {
+		if v == s {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,296 @@
+package dasql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestScanPlaceholders(t *testing.T) {
+	for i, c := range []struct {
+		q   string
+		d   Dialect
+		exp []string
+	}{
+		{`SELECT * FROM foo WHERE a = :a AND b = :b_2`, DialectNone, []string{":a", ":b_2"}},
+		{`SELECT :a::int, x:=1, arr[1:2], '::' || ':no'`, DialectNone, []string{":a"}},
+		{`SELECT ':no', ":no", ` + "`:no`" + `, 'it''s :no' -- :no
+			/* :no */ :yes`, DialectNone, []string{":yes"}},
+		{`SELECT $$ :no $$, $tag$ :no $tag$, :yes`, DialectNone, []string{":yes"}},
+		{`SELECT 'a\' :no', ? # :no` + "\n" + `, :yes`, DialectMySQL, []string{"?", ":yes"}},
+		{`SELECT "a\"b", ?, ?`, DialectMySQL, []string{"?", "?"}},
+		{`SELECT a$b, ?`, DialectMySQL, []string{"?"}},
+		{`SELECT $1, $12, ?, E'\' $1', '\' , $2`, DialectPostgres, []string{"$1", "$12", "$2"}},
+		{`SELECT /* /* $1 */ $1 */ $2, $f$ $3 $f$`, DialectPostgres, []string{"$2"}},
+		{`SELECT 'it\'s :no', "a\" :no", :yes`, DialectNone, []string{":yes"}},
+		{`SELECT 'unterminated :no`, DialectNone, nil},
+		{`SELECT /* unterminated :no`, DialectNone, nil},
+		{`SELECT $$ unterminated :no`, DialectNone, nil},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var act []string
+			for _, ph := range scanPlaceholders(c.q, c.d) {
+				act = append(act, c.q[ph.start:ph.end])
+			}
+
+			if !reflect.DeepEqual(act, c.exp) {
+				t.Fatalf("exp: %v got: %v", c.exp, act)
+			}
+		})
+	}
+}
+
+func TestRewritePositional(t *testing.T) {
+	for i, c := range []struct {
+		q       string
+		d       Dialect
+		args    []interface{}
+		exp     string
+		expArgs []interface{}
+		expErr  error
+	}{
+		{
+			q: `SELECT ?`, d: DialectNone, args: []interface{}{1},
+			exp: `SELECT ?`, expArgs: []interface{}{1},
+		},
+		{
+			q: `SELECT * FROM foo WHERE a = ? AND b = '?' AND c = :c AND d = ?`, d: DialectMySQL,
+			args: []interface{}{1, sql.Named("c", 2), "3"},
+			exp:  `SELECT * FROM foo WHERE a = :p1 AND b = '?' AND c = :c AND d = :p2`,
+			expArgs: []interface{}{
+				sql.Named("p1", 1), sql.Named("c", 2), sql.Named("p2", "3")},
+		},
+		{
+			q: `SELECT $2, $1, $2, '$3'`, d: DialectPostgres, args: []interface{}{1, 2},
+			exp: `SELECT :p2, :p1, :p2, '$3'`, expArgs: []interface{}{sql.Named("p1", 1), sql.Named("p2", 2)},
+		},
+		{
+			q: `SELECT :a`, d: DialectMySQL, args: []interface{}{sql.Named("a", 1)},
+			exp: `SELECT :a`, expArgs: []interface{}{sql.Named("a", 1)},
+		},
+		{
+			q: `SELECT ?, :p1`, d: DialectMySQL, args: []interface{}{1, sql.Named("p1", 2)},
+			expErr: ArgErr{Kind: ArgErrKindDuplicate, Names: []string{"p1"}},
+		},
+		{
+			q: `SELECT ?, :p2`, d: DialectMySQL, args: []interface{}{1, sql.Named("p2", 2)},
+			exp: `SELECT :p1, :p2`, expArgs: []interface{}{sql.Named("p1", 1), sql.Named("p2", 2)},
+		},
+		{
+			q: `SELECT ?, ?`, d: DialectMySQL, args: []interface{}{1},
+			expErr: ArgErr{Kind: ArgErrKindPositional},
+		},
+		{
+			q: `SELECT ?`, d: DialectMySQL, args: []interface{}{1, 2},
+			expErr: ArgErr{Kind: ArgErrKindPositional},
+		},
+		{
+			q: `SELECT $3`, d: DialectPostgres, args: []interface{}{1, 2},
+			expErr: ArgErr{Kind: ArgErrKindPositional, Type: "$3"},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			act, actArgs, err := rewritePositional(c.q, c.d, c.args)
+			if !errors.Is(err, c.expErr) {
+				t.Fatalf("exp: %v got: %v", c.expErr, err)
+			}
+
+			if act != c.exp {
+				t.Fatalf("exp: %v got: %v", c.exp, act)
+			}
+
+			if !reflect.DeepEqual(actArgs, c.expArgs) {
+				t.Fatalf("exp: %v got: %v", c.expArgs, actArgs)
+			}
+		})
+	}
+}
+
+func TestDBQueryPositional(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{}}, context.Background()
+	db := New(da, "", "", WithDialect(DialectPostgres))
+
+	if _, err := db.Query(ctx, `SELECT * FROM foo WHERE bar = $1`, "foo"); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.Sql); act != `SELECT * FROM foo WHERE bar = :p1` {
+		t.Fatalf("got: %v", act)
+	}
+
+	exp := `[{Name:"p1",Value:{StringValue:"foo"}}]`
+	if act := strings.Join(strings.Fields(fmt.Sprintf("%s", da.lastESI.Parameters)), ""); act != exp {
+		t.Fatalf("exp: %v got: %v", exp, act)
+	}
+
+	_, err := db.Exec(ctx, `DELETE FROM foo WHERE bar = $1`)
+	if aerr := (ArgErr{}); !errors.As(err, &aerr) || aerr.Kind != ArgErrKindPositional {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestDBBatchPositional(t *testing.T) {
+	da, ctx := &stubDA{nextBESO: &rdsdataservice.BatchExecuteStatementOutput{}}, context.Background()
+	db := New(da, "", "", WithDialect(DialectMySQL))
+
+	b := NewBatch(`INSERT INTO foo (bar) VALUES (?)`).Exec(1).Exec(2)
+	if _, err := db.ExecBatch(ctx, b); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastBESI.Sql); act != `INSERT INTO foo (bar) VALUES (:p1)` {
+		t.Fatalf("got: %v", act)
+	}
+
+	if len(da.lastBESI.ParameterSets) != 2 ||
+		aws.Int64Value(da.lastBESI.ParameterSets[1][0].Value.LongValue) != 2 {
+		t.Fatalf("got: %v", da.lastBESI.ParameterSets)
+	}
+}
+
+func TestDriverPositional(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{}}, context.Background()
+	db := sql.OpenDB(NewConnector(New(da, "", "", WithDialect(DialectMySQL))))
+
+	if _, err := db.ExecContext(ctx, `UPDATE foo SET bar = ? WHERE id = ?`, "foo", 1); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.Sql); act != `UPDATE foo SET bar = :p1 WHERE id = :p2` {
+		t.Fatalf("got: %v", act)
+	}
+}
+
+func TestRewriteNamed(t *testing.T) {
+	for i, c := range []struct {
+		q       string
+		d       Dialect
+		args    []interface{}
+		exp     string
+		expArgs []interface{}
+		expErr  error
+	}{
+		{
+			q: `SELECT :a, :b, :a, ':c'`, d: DialectMySQL,
+			args: []interface{}{sql.Named("b", 2), sql.Named("a", 1)},
+			exp:  `SELECT ?, ?, ?, ':c'`, expArgs: []interface{}{1, 2, 1},
+		},
+		{
+			q: `SELECT :a, :b, :a::int`, d: DialectPostgres,
+			args: []interface{}{sql.Named("b", 2), sql.Named("a", 1)},
+			exp:  `SELECT $1, $2, $1::int`, expArgs: []interface{}{1, 2},
+		},
+		{
+			q: `SELECT ?`, d: DialectMySQL, args: []interface{}{1},
+			exp: `SELECT ?`, expArgs: []interface{}{1},
+		},
+		{
+			q: `SELECT :a, :b, :b`, d: DialectMySQL, args: []interface{}{sql.Named("c", 1)},
+			expErr: ArgErr{Kind: ArgErrKindMissing, Names: []string{"a", "b"}},
+		},
+		{
+			q: `SELECT :a`, d: DialectMySQL, args: []interface{}{1},
+			expErr: ArgErr{Kind: ArgErrKindPositional},
+		},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			act, actArgs, err := rewriteNamedArgs(c.q, c.d, c.args)
+			if !errors.Is(err, c.expErr) {
+				t.Fatalf("exp: %v got: %v", c.expErr, err)
+			}
+
+			if act != c.exp {
+				t.Fatalf("exp: %v got: %v", c.exp, act)
+			}
+
+			if !reflect.DeepEqual(actArgs, c.expArgs) {
+				t.Fatalf("exp: %v got: %v", c.expArgs, actArgs)
+			}
+		})
+	}
+}
+
+func TestParseNamed(t *testing.T) {
+	for i, c := range []struct {
+		q   string
+		exp []string
+	}{
+		{`SELECT * FROM foo WHERE a = :a AND b = :b OR a = :a`, []string{"a", "b"}},
+		{`SELECT :a::int, ':no' -- :no` + "\n" + `/* :no */ FROM foo`, []string{"a"}},
+		{`SELECT 1`, nil},
+		{`UPDATE t SET a = 'it\'s' WHERE b = :b`, []string{"b"}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if act := ParseNamed(c.q); !reflect.DeepEqual(act, c.exp) {
+				t.Fatalf("exp: %v got: %v", c.exp, act)
+			}
+		})
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	params := func(names ...string) (ps []*rdsdataservice.SqlParameter) {
+		for _, name := range names {
+			ps = append(ps, &rdsdataservice.SqlParameter{Name: aws.String(name)})
+		}
+		return
+	}
+
+	for i, c := range []struct {
+		q      string
+		params []*rdsdataservice.SqlParameter
+		expErr error
+	}{
+		{`SELECT :a, :b, :a`, params("b", "a"), nil},
+		{`SELECT 1`, nil, nil},
+		{`SELECT :a`, params("a", "b", "c"), ArgErr{Kind: ArgErrKindUnused, Names: []string{"b", "c"}}},
+		{`SELECT :a, :b, :c`, params("b"), ArgErr{Kind: ArgErrKindMissing, Names: []string{"a", "c"}}},
+		{`SELECT :a`, params("a", "a"), ArgErr{Kind: ArgErrKindDuplicate, Names: []string{"a"}}},
+		{`UPDATE t SET a = 'it\'s' WHERE b = :b`, params("b"), nil},
+		{`UPDATE t SET a = 'it\'s' WHERE b = :b`, params("b", "c"), nil},
+		{`UPDATE t SET a = 'C:\' WHERE b = :b`, params("b"), nil},
+		{`SELECT :a`, params("a", "", "1a", "a-b", "a$"),
+			ArgErr{Kind: ArgErrKindInvalidName, Names: []string{"", "1a", "a-b", "a$"}}},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if err := validateParams(c.q, DialectNone, c.params); !errors.Is(err, c.expErr) {
+				t.Fatalf("exp: %v got: %v", c.expErr, err)
+			}
+		})
+	}
+}
+
+func TestDBValidateArgs(t *testing.T) {
+	da, ctx := &stubDA{}, context.Background()
+	db := New(da, "", "")
+
+	_, err := db.Exec(ctx, `UPDATE foo SET a = :a WHERE id = :id`, sql.Named("a", 1), sql.Named("ID", 1))
+	if !errors.Is(err, ArgErr{Kind: ArgErrKindMissing, Names: []string{"id"}}) {
+		t.Fatalf("got: %v", err)
+	}
+
+	_, err = db.ExecBatch(ctx, NewBatch(`DELETE FROM foo WHERE id = :id`).
+		Exec(sql.Named("id", 1)).
+		Exec(sql.Named("id", 2), sql.Named("id", 3)))
+	if !errors.Is(err, ArgErr{Kind: ArgErrKindDuplicate, Names: []string{"id"}}) {
+		t.Fatalf("got: %v", err)
+	}
+
+	if da.lastESI != nil || da.lastBESI != nil {
+		t.Fatalf("should not have called the Data API")
+	}
+
+	// a backslash-escaped quote is valid for Aurora MySQL
+	da.nextESO = &rdsdataservice.ExecuteStatementOutput{}
+	if _, err = db.Exec(ctx, `UPDATE t SET a = 'it\'s' WHERE b = :b`, sql.Named("b", 1)); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+}
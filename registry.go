@@ -0,0 +1,98 @@
+package dasql
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// Encoder converts an argument into a Data API field and an optional type hint
+type Encoder func(v interface{}) (f *rdsdataservice.Field, hint string, err error)
+
+// Decoder scans a Data API field into the destination 'dst', which is always a pointer
+type Decoder func(f *rdsdataservice.Field, dst interface{}) error
+
+// ColumnDecoderFunc scans a Data API field of a certain column type into the destination 'dst'.
+// Destinations it doesn't know should be passed to 'next', which scans with the built-in
+// conversions and the options of the DB.
+type ColumnDecoderFunc func(f *rdsdataservice.Field, dst interface{}, next Decoder) error
+
+// TypeRegistry holds custom conversions for project specific types, see WithTypes. Encoders and
+// decoders are consulted before the built-in conversions. A registry should not be changed once
+// it is used by a DB.
+type TypeRegistry struct {
+	encoders map[reflect.Type]Encoder
+	decoders map[reflect.Type]Decoder
+	columns  map[string]ColumnDecoderFunc
+}
+
+// NewTypeRegistry initializes an empty type registry
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{
+		encoders: map[reflect.Type]Encoder{},
+		decoders: map[reflect.Type]Decoder{},
+		columns:  map[string]ColumnDecoderFunc{},
+	}
+}
+
+// Encoder registers the encoder for arguments of type 't'. The type must match exactly, so
+// register pointer types separately if they are used as arguments.
+func (r *TypeRegistry) Encoder(t reflect.Type, enc Encoder) *TypeRegistry {
+	r.encoders[t] = enc
+	return r
+}
+
+// Decoder registers the decoder for scan destinations that are a pointer to type 't'
+func (r *TypeRegistry) Decoder(t reflect.Type, dec Decoder) *TypeRegistry {
+	r.decoders[t] = dec
+	return r
+}
+
+// ColumnDecoder registers the decoder for columns with the database type 'name' (as reported by
+// the column metadata, the case is ignored). It is used for every destination that has no decoder
+// for its type, so it should pass destinations it doesn't know to the 'next' decoder.
+func (r *TypeRegistry) ColumnDecoder(name string, dec ColumnDecoderFunc) *TypeRegistry {
+	r.columns[strings.ToUpper(name)] = dec
+	return r
+}
+
+// encoder returns the encoder for the argument, if any
+func (r *TypeRegistry) encoder(arg interface{}) Encoder {
+	if r == nil || arg == nil {
+		return nil
+	}
+
+	return r.encoders[reflect.TypeOf(arg)]
+}
+
+// decodes returns whether a decoder is registered for destinations that point to type 't'
+func (r *TypeRegistry) decodes(t reflect.Type) bool {
+	if r == nil {
+		return false
+	}
+
+	_, ok := r.decoders[t]
+	return ok
+}
+
+// decoder returns the decoder for the destination or otherwise for the column type, if any. The
+// decoder of a column type falls back to 'next'.
+func (r *TypeRegistry) decoder(typeName string, dst interface{}, next Decoder) Decoder {
+	if r == nil {
+		return nil
+	}
+
+	if t := reflect.TypeOf(dst); t != nil && t.Kind() == reflect.Ptr {
+		if dec, ok := r.decoders[t.Elem()]; ok {
+			return dec
+		}
+	}
+
+	dec, ok := r.columns[strings.ToUpper(typeName)]
+	if !ok {
+		return nil
+	}
+
+	return func(f *rdsdataservice.Field, dst interface{}) error { return dec(f, dst, next) }
+}
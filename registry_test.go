@@ -0,0 +1,134 @@
+package dasql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+type testPoint struct{ X, Y float64 }
+
+func testTypes() *TypeRegistry {
+	return NewTypeRegistry().
+		Encoder(reflect.TypeOf(testPoint{}), func(v interface{}) (*rdsdataservice.Field, string, error) {
+			p := v.(testPoint)
+			return &rdsdataservice.Field{StringValue: aws.String(fmt.Sprintf("POINT(%v %v)", p.X, p.Y))}, "", nil
+		}).
+		Encoder(reflect.TypeOf(testStatus("")), func(v interface{}) (*rdsdataservice.Field, string, error) {
+			return nil, "", errors.New("invalid status")
+		}).
+		Decoder(reflect.TypeOf(testPoint{}), func(f *rdsdataservice.Field, dst interface{}) error {
+			p := dst.(*testPoint)
+			_, err := fmt.Sscanf(aws.StringValue(f.StringValue), "POINT(%v %v)", &p.X, &p.Y)
+			return err
+		}).
+		ColumnDecoder("money", func(f *rdsdataservice.Field, dst interface{}, next Decoder) error {
+			if _, ok := dst.(*int); !ok {
+				return next(f, dst)
+			}
+
+			return next(&rdsdataservice.Field{LongValue: aws.Int64(100)}, dst)
+		})
+}
+
+func TestTypeRegistryArgs(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{}}, context.Background()
+	db := New(da, "", "", WithTypes(testTypes()))
+
+	if _, err := db.Exec(ctx, `UPDATE foo SET loc = :loc`, sql.Named("loc", testPoint{1, 2.5})); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastESI.Parameters[0].Value.StringValue); act != "POINT(1 2.5)" {
+		t.Fatalf("got: %v", act)
+	}
+
+	_, err := db.Exec(ctx, `UPDATE foo SET status = :s`, sql.Named("s", testStatus("foo")))
+	if !errors.Is(err, ArgErr{Kind: ArgErrKindValuer, Type: "dasql.testStatus"}) {
+		t.Fatalf("got: %v", err)
+	}
+
+	// the registry is not used without the option
+	if _, _, err = defaultCodec.convertArg(testPoint{}); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestTypeRegistryScan(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{
+		ColumnMetadata: []*rdsdataservice.ColumnMetadata{
+			{Name: aws.String("loc"), TypeName: aws.String("geometry")},
+			{Name: aws.String("price"), TypeName: aws.String("MONEY")},
+		},
+		Records: [][]*rdsdataservice.Field{
+			{{StringValue: aws.String("POINT(1 2.5)")}, {StringValue: aws.String("$1.00")}},
+			{{StringValue: aws.String("foo")}, {StringValue: aws.String("$1.00")}},
+		},
+	}}, context.Background()
+
+	rows, err := New(da, "", "", WithTypes(testTypes())).Query(ctx, `SELECT loc, price FROM foo`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var p testPoint
+	var price int
+	rows.Next()
+	if err = rows.Scan(&p, &price); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if p != (testPoint{1, 2.5}) || price != 100 {
+		t.Fatalf("got: %v %v", p, price)
+	}
+
+	// other destinations are passed to the built-in conversions
+	var sprice string
+	if err = rows.Scan(&p, &sprice); err != nil || sprice != "$1.00" {
+		t.Fatalf("got: %v %v", sprice, err)
+	}
+
+	rows.Next()
+	var se ScanErr
+	if err = rows.Scan(&p, &price); !errors.As(err, &se) ||
+		se.Kind != ScanErrKindTypeMismatch || se.Column != "loc" || se.Row != 1 {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestColumnDecoderNext(t *testing.T) {
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{
+		ColumnMetadata: []*rdsdataservice.ColumnMetadata{
+			{Name: aws.String("at"), TypeName: aws.String("timestamptz")}},
+		Records: [][]*rdsdataservice.Field{{{StringValue: aws.String("2020-01-02 03:04:05")}}},
+	}}, context.Background()
+
+	var called bool
+	cet := time.FixedZone("CET", 3600)
+	rows, err := New(da, "", "", WithLocation(cet), WithTypes(NewTypeRegistry().
+		ColumnDecoder("timestamptz", func(f *rdsdataservice.Field, dst interface{}, next Decoder) error {
+			called = true
+			return next(f, dst)
+		}))).Query(ctx, `SELECT at FROM foo`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var at time.Time
+	rows.Next()
+	if err = rows.Scan(&at); err != nil || !called {
+		t.Fatalf("got: %v %v", called, err)
+	}
+
+	// the next decoder scans with the options of the DB
+	if at.Location() != cet || at.Hour() != 3 {
+		t.Fatalf("got: %v", at)
+	}
+}
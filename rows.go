@@ -2,7 +2,10 @@ package dasql
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/rdsdataservice"
 )
 
@@ -13,10 +16,102 @@ type Rows interface {
 	Close() error
 }
 
+// ColumnRows is implemented by rows that describe their columns, which all rows returned by this
+// package do. See Columns and ColumnTypes.
+type ColumnRows interface {
+	// Columns returns the column names, similar to sql.Rows.Columns
+	Columns() ([]string, error)
+
+	// ColumnTypes returns the column information, similar to sql.Rows.ColumnTypes
+	ColumnTypes() ([]ColumnType, error)
+}
+
+// StructRows is implemented by rows that scan into structs themselves, see ScanStruct
+type StructRows interface {
+	ScanStruct(dst interface{}) error
+}
+
+// ErrRows is implemented by rows that fetch their results while iterating, see RowsErr
+type ErrRows interface {
+	Err() error
+}
+
+// Columns returns the column names of 'rows', similar to sql.Rows.Columns. It fails if the rows
+// don't implement ColumnRows.
+func Columns(rows Rows) ([]string, error) {
+	cr, ok := rows.(ColumnRows)
+	if !ok {
+		return nil, fmt.Errorf("dasql: rows of type %T don't describe their columns", rows)
+	}
+
+	return cr.Columns()
+}
+
+// ColumnTypes returns the column information of 'rows', similar to sql.Rows.ColumnTypes. It
+// fails if the rows don't implement ColumnRows.
+func ColumnTypes(rows Rows) ([]ColumnType, error) {
+	cr, ok := rows.(ColumnRows)
+	if !ok {
+		return nil, fmt.Errorf("dasql: rows of type %T don't describe their columns", rows)
+	}
+
+	return cr.ColumnTypes()
+}
+
+// ScanStruct scans the current row into the fields of the struct 'dst' points to. Fields are
+// matched by their 'db' tag or else by their name as mapped by the NameMapper. Fields with the
+// 'json' tag option, e.g: `db:"payload,json"`, are unmarshalled from a JSON column. Rows that
+// don't implement StructRows are mapped by their Columns with the default mapper.
+func ScanStruct(rows Rows, dst interface{}) error {
+	if sr, ok := rows.(StructRows); ok {
+		return sr.ScanStruct(dst)
+	}
+
+	cols, err := Columns(rows)
+	if err != nil {
+		return err
+	}
+
+	dest, err := defaultMapper.dest(cols, dst)
+	if err != nil {
+		return err
+	}
+
+	return rows.Scan(dest...)
+}
+
+// RowsErr returns the error, if any, that was encountered while iterating 'rows'. It is only
+// set for results that are fetched while iterating, see DB.QueryPaged and QueryCursor.
+func RowsErr(rows Rows) error {
+	if er, ok := rows.(ErrRows); ok {
+		return er.Err()
+	}
+
+	return nil
+}
+
+// ColumnType describes a column of the result. It offers the same methods as sql.ColumnType
+// with the addition of signedness and the table name that the Data API returns.
+type ColumnType interface {
+	Name() string
+	DatabaseTypeName() string
+	Nullable() (nullable, ok bool)
+	DecimalSize() (precision, scale int64, ok bool)
+	Length() (length int64, ok bool)
+	ScanType() reflect.Type
+	Signed() (signed, ok bool)
+	TableName() (name string, ok bool)
+}
+
 // daRows implements the Rows interface for the Data API
 type daRows struct {
 	recs [][]*rdsdataservice.Field
 	pos  int
+	cols []*rdsdataservice.ColumnMetadata
+
+	mapper *structMapper
+	codec  *codec
+	off    int // index of the first record in the complete result, for paged results
 }
 
 // Next will prepare the next results for scanning
@@ -36,8 +131,160 @@ func (r *daRows) Scan(dest ...interface{}) (err error) {
 		return errors.New("dasql: not enough arguments to scan row")
 	}
 
-	return Scan(r.recs[r.pos], dest...)
+	c := r.codec
+	if c == nil {
+		c = defaultCodec
+	}
+
+	err = c.scan(r.cols, r.recs[r.pos], dest...)
+
+	var se ScanErr
+	if errors.As(err, &se) {
+		se.Row = r.off + r.pos
+		if se.Field < len(r.cols) {
+			se.Column = daColumnType{r.cols[se.Field]}.Name()
+		}
+
+		return se
+	}
+
+	return err
 }
 
+// ScanStruct scans the current result set into the fields of a struct
+func (r *daRows) ScanStruct(dst interface{}) error {
+	cols, err := r.Columns()
+	if err != nil {
+		return err
+	}
+
+	m := r.mapper
+	if m == nil {
+		m = defaultMapper
+	}
+
+	dest, err := m.dest(cols, dst)
+	if err != nil {
+		return err
+	}
+
+	return r.Scan(dest...)
+}
+
+// decodes returns whether the type registry of the rows has a decoder for type 't'
+func (r *daRows) decodes(t reflect.Type) bool { return r.codec != nil && r.codec.types.decodes(t) }
+
+// Err always returns nil since all the records are read at once
+func (r *daRows) Err() error { return nil }
+
 // Close does nothing for Data API abstraction since ther is no cursor to close
 func (r *daRows) Close() error { return nil }
+
+// Columns returns the column names, the label is used if the column was aliased
+func (r *daRows) Columns() ([]string, error) {
+	if r.cols == nil {
+		return nil, errors.New("dasql: no column metadata in the result")
+	}
+
+	names := make([]string, len(r.cols))
+	for i, cm := range r.cols {
+		names[i] = daColumnType{cm}.Name()
+	}
+
+	return names, nil
+}
+
+// ColumnTypes returns the column types as described by the Data API's column metadata
+func (r *daRows) ColumnTypes() ([]ColumnType, error) {
+	if r.cols == nil {
+		return nil, errors.New("dasql: no column metadata in the result")
+	}
+
+	cts := make([]ColumnType, len(r.cols))
+	for i, cm := range r.cols {
+		cts[i] = daColumnType{cm}
+	}
+
+	return cts, nil
+}
+
+// daColumnType implements ColumnType for the Data API's column metadata
+type daColumnType struct {
+	cm *rdsdataservice.ColumnMetadata
+}
+
+// Name returns the label of the column, or its name if it has no label
+func (ct daColumnType) Name() string {
+	if l := aws.StringValue(ct.cm.Label); l != "" {
+		return l
+	}
+
+	return aws.StringValue(ct.cm.Name)
+}
+
+// DatabaseTypeName returns the database type name, such as "VARCHAR" or "INT"
+func (ct daColumnType) DatabaseTypeName() string { return aws.StringValue(ct.cm.TypeName) }
+
+// Nullable returns whether the column may be null, ok is false if that is unknown
+func (ct daColumnType) Nullable() (nullable, ok bool) {
+	switch aws.Int64Value(ct.cm.Nullable) {
+	case 0: // no nulls
+		return false, true
+	case 1: // nullable
+		return true, true
+	default: // unknown
+		return false, false
+	}
+}
+
+// DecimalSize returns the precision and scale of decimal columns
+func (ct daColumnType) DecimalSize() (precision, scale int64, ok bool) {
+	if columnKind(ct.DatabaseTypeName()) != kindDecimal || ct.cm.Precision == nil {
+		return 0, 0, false
+	}
+
+	return aws.Int64Value(ct.cm.Precision), aws.Int64Value(ct.cm.Scale), true
+}
+
+// Length returns the length of text and binary columns
+func (ct daColumnType) Length() (length int64, ok bool) {
+	switch columnKind(ct.DatabaseTypeName()) {
+	case kindString, kindBlob:
+		return aws.Int64Value(ct.cm.Precision), ct.cm.Precision != nil
+	default:
+		return 0, false
+	}
+}
+
+// ScanType returns the Go type of the values this package scans from the column's fields
+func (ct daColumnType) ScanType() reflect.Type {
+	switch columnKind(ct.DatabaseTypeName()) {
+	case kindLong:
+		return reflect.TypeOf(int64(0))
+	case kindDouble:
+		return reflect.TypeOf(float64(0))
+	case kindBool:
+		return reflect.TypeOf(false)
+	case kindBlob:
+		return reflect.TypeOf([]byte{})
+	case kindString, kindDecimal, kindDate, kindTime, kindTimestamp:
+		return reflect.TypeOf("")
+	default:
+		return reflect.TypeOf(new(interface{})).Elem()
+	}
+}
+
+// Signed returns whether a numeric column is signed
+func (ct daColumnType) Signed() (signed, ok bool) {
+	switch columnKind(ct.DatabaseTypeName()) {
+	case kindLong, kindDouble, kindDecimal:
+		return aws.BoolValue(ct.cm.IsSigned), ct.cm.IsSigned != nil
+	default:
+		return false, false
+	}
+}
+
+// TableName returns the name of the table the column belongs to, if any
+func (ct daColumnType) TableName() (name string, ok bool) {
+	return aws.StringValue(ct.cm.TableName), aws.StringValue(ct.cm.TableName) != ""
+}
@@ -1,6 +1,7 @@
 package dasql
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -49,3 +50,102 @@ func TestRowScanErrors(t *testing.T) {
 		}
 	})
 }
+
+func TestRowColumns(t *testing.T) {
+	res := &daRows{pos: -1, cols: []*rdsdataservice.ColumnMetadata{
+		{Name: aws.String("id"), TypeName: aws.String("INT UNSIGNED"), Nullable: aws.Int64(0),
+			IsSigned: aws.Bool(false), TableName: aws.String("foo"), Precision: aws.Int64(10)},
+		{Name: aws.String("price"), Label: aws.String("p"), TypeName: aws.String("DECIMAL"),
+			Nullable: aws.Int64(1), Precision: aws.Int64(10), Scale: aws.Int64(2)},
+		{Name: aws.String("bar"), TypeName: aws.String("VARCHAR"), Nullable: aws.Int64(2),
+			Precision: aws.Int64(255)},
+	}}
+
+	cols, err := res.Columns()
+	if err != nil || strings.Join(cols, ",") != "id,p,bar" {
+		t.Fatalf("got: %v %v", cols, err)
+	}
+
+	cts, err := res.ColumnTypes()
+	if err != nil || len(cts) != 3 {
+		t.Fatalf("got: %v %v", cts, err)
+	}
+
+	if tn, ok := cts[0].TableName(); !ok || tn != "foo" {
+		t.Fatalf("got: %v %v", tn, ok)
+	}
+
+	if s, ok := cts[0].Signed(); !ok || s {
+		t.Fatalf("got: %v %v", s, ok)
+	}
+
+	if n, ok := cts[0].Nullable(); !ok || n {
+		t.Fatalf("got: %v %v", n, ok)
+	}
+
+	if act := cts[0].ScanType().Kind().String(); act != "int64" {
+		t.Fatalf("got: %v", act)
+	}
+
+	if p, s, ok := cts[1].DecimalSize(); !ok || p != 10 || s != 2 {
+		t.Fatalf("got: %v %v %v", p, s, ok)
+	}
+
+	if n, ok := cts[1].Nullable(); !ok || !n {
+		t.Fatalf("got: %v %v", n, ok)
+	}
+
+	if _, ok := cts[1].Length(); ok {
+		t.Fatalf("got: %v", ok)
+	}
+
+	if l, ok := cts[2].Length(); !ok || l != 255 {
+		t.Fatalf("got: %v %v", l, ok)
+	}
+
+	if _, ok := cts[2].Nullable(); ok {
+		t.Fatalf("got: %v", ok)
+	}
+
+	if _, ok := cts[2].Signed(); ok {
+		t.Fatalf("got: %v", ok)
+	}
+
+	if _, err = (&daRows{}).Columns(); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err = (&daRows{}).ColumnTypes(); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+// plainRows implements only the Rows interface, like an implementation outside this package
+type plainRows struct{}
+
+func (plainRows) Next() bool                     { return false }
+func (plainRows) Scan(dest ...interface{}) error { return nil }
+func (plainRows) Close() error                   { return nil }
+
+func TestOptionalRows(t *testing.T) {
+	if _, err := Columns(plainRows{}); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err := ColumnTypes(plainRows{}); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var u struct{ ID int64 }
+	if err := ScanStruct(plainRows{}, &u); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err := RowsErr(plainRows{}); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err := RowsErr(&pagedRows{err: errors.New("foo")}); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+}
@@ -2,8 +2,14 @@ package dasql
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/rdsdataservice"
@@ -14,6 +20,7 @@ type ScanErr struct {
 	Kind       ScanErrKind
 	err        error
 	Row, Field int
+	Column     string // name of the column, if known
 }
 
 // ScanErrKind indicates certain behaviour
@@ -37,6 +44,31 @@ const (
 
 	// ScanErrTooManyFields is returned when there are more fields then scan values
 	ScanErrTooManyFields
+
+	// ScanErrKindNoField is returned when a column has no matching field in the struct
+	ScanErrKindNoField
+
+	// ScanErrKindUnmarshal is returned when a JSON value can't be unmarshalled into the destination
+	ScanErrKindUnmarshal
+
+	// ScanErrKindNull is returned when a NULL field is scanned into a destination that can't hold it
+	ScanErrKindNull
+)
+
+// NullPolicy determines what happens when a NULL field is scanned into a destination that is not
+// a sql.Scanner, see WithNullPolicy.
+type NullPolicy int
+
+const (
+	// NullAsNil sets pointer destinations (**T, *interface{}, *[]byte) to nil and returns an error
+	// for all other destinations. This is the default and follows the database/sql package.
+	NullAsNil NullPolicy = iota
+
+	// NullAsZero sets the destination to its zero value
+	NullAsZero
+
+	// NullAsError returns an error for every destination, only a sql.Scanner can hold a NULL
+	NullAsError
 )
 
 // Unwrap returns the underlying error, if any
@@ -46,15 +78,55 @@ func (se ScanErr) Unwrap() error {
 
 // Error implements the error interface
 func (se ScanErr) Error() string {
+	if se.Column != "" {
+		return fmt.Sprintf("failed to scan field %d (%s) of row %d: %v",
+			se.Field, se.Column, se.Row, se.err)
+	}
+
 	return fmt.Sprintf("failed to scan field %d of row %d: %v", se.Field, se.Row, se.err)
 }
 
 // Scan copies fields frow row 'row' into the valuees pointed to by 'dest'. The number of values in
 // dest must be the same as the number of columns in the row.
+//
+// Destinations are converted like database/sql does it: *string, *[]byte, *sql.RawBytes, *bool,
+// *interface{}, sql.Scanner and all integer and float widths. Numbers are parsed from strings and
+// formatted into strings, integers are checked for overflow. A pointer to a pointer (**T) is set
+// to nil for a NULL field and allocated otherwise. In addition strings scan into *time.Time and
+// *sql.NullTime (see WithLocation), encoding.TextUnmarshaler and the *big.Rat, *big.Float and
+// *big.Int decimal types. Array fields scan into (nested) slices of the matching type.
 func Scan(row []*rdsdataservice.Field, dest ...interface{}) (err error) {
+	return defaultCodec.scan(nil, row, dest...)
+}
+
+// scan copies the fields of the row into the dest values, scan errors are annotated with the
+// index of the field that failed. The column metadata is optional, it is used to find decoders
+// for the column types.
+func (c *codec) scan(
+	cols []*rdsdataservice.ColumnMetadata, row []*rdsdataservice.Field, dest ...interface{},
+) (err error) {
 	for i, f := range row {
-		err = scanField(f, dest[i])
+		var typeName string
+		if i < len(cols) {
+			typeName = aws.StringValue(cols[i].TypeName)
+		}
+
+		if dec := c.types.decoder(typeName, dest[i], c.scanField); dec != nil {
+			err = dec(f, dest[i])
+			if err != nil && !errors.As(err, &ScanErr{}) {
+				err = ScanErr{Kind: ScanErrKindTypeMismatch, err: err}
+			}
+		} else {
+			err = c.scanField(f, dest[i])
+		}
+
 		if err != nil {
+			var se ScanErr
+			if errors.As(err, &se) {
+				se.Field = i
+				return se
+			}
+
 			return
 		}
 	}
@@ -62,78 +134,278 @@ func Scan(row []*rdsdataservice.Field, dest ...interface{}) (err error) {
 	return
 }
 
-// scanField will attempt to scan the provided field into 'dst' if it has a supported type
-func scanField(src *rdsdataservice.Field, dst interface{}) (err error) {
-	var expType string
+// scanField will attempt to scan the provided field into 'dst', see Scan for the conversions that
+// are supported.
+func (c *codec) scanField(src *rdsdataservice.Field, dst interface{}) (err error) {
+	if src.ArrayValue != nil && !aws.BoolValue(src.IsNull) {
+		if p, ok := dst.(*interface{}); ok {
+			if *p, err = arrayValue(src.ArrayValue); err != nil {
+				return ScanErr{Kind: ScanErrKindUnsupported, err: err}
+			}
 
-	switch {
-	case src.IsNull != nil && *src.IsNull == true:
-		expType = "sql.Scanner"
-		if dt, ok := dst.(sql.Scanner); ok {
-			return dt.Scan(nil)
+			return nil
 		}
 
-	case src.ArrayValue != nil:
 		return scanArrayValue(src.ArrayValue, dst, 0)
+	}
+
+	v, err := fieldValue(src)
+	if err != nil {
+		return ScanErr{Kind: ScanErrKindUnsupported, err: err}
+	}
 
-	case src.StringValue != nil:
-		expType = "*string,sql.Scanner"
-		switch p := dst.(type) {
+	return c.convertAssign(dst, v)
+}
+
+// convertAssign copies the field value 'src' into 'dst'. It follows the conversion rules of the
+// database/sql package, with additional support for times, decimals and the Data API's lack of
+// specific field types.
+func (c *codec) convertAssign(dst interface{}, src driver.Value) error {
+	if src == nil {
+		return c.assignNull(dst)
+	}
+
+	switch s := src.(type) {
+	case string:
+		switch d := dst.(type) {
 		case *string:
-			*p, expType = aws.StringValue(src.StringValue), ""
-		case sql.Scanner:
-			return p.Scan(*src.StringValue)
+			*d = s
+			return nil
+		case *[]byte:
+			*d = []byte(s)
+			return nil
+		case *sql.RawBytes:
+			*d = append((*d)[:0], s...)
+			return nil
+		case *time.Time:
+			t, err := c.parseTime(s)
+			if err != nil {
+				return ScanErr{Kind: ScanErrKindTypeMismatch, err: err}
+			}
+
+			*d = t
+			return nil
+		case *sql.NullTime:
+			t, err := c.parseTime(s)
+			if err != nil {
+				return ScanErr{Kind: ScanErrKindTypeMismatch, err: err}
+			}
+
+			*d = sql.NullTime{Time: t, Valid: true}
+			return nil
+		case *big.Rat, *big.Float, *big.Int:
+			return scanDecimal(s, d)
+		}
+	case int64:
+		switch d := dst.(type) {
+		case *big.Int:
+			d.SetInt64(s)
+			return nil
+		case *big.Rat:
+			d.SetInt64(s)
+			return nil
+		case *big.Float:
+			d.SetInt64(s)
+			return nil
+		}
+	case float64:
+		switch d := dst.(type) {
+		case *big.Rat, *big.Float:
+			return scanFloatDecimal(s, d)
+		}
+	case []byte:
+		switch d := dst.(type) {
+		case *string:
+			*d = string(s)
+			return nil
+		case *interface{}:
+			*d = cloneBytes(s)
+			return nil
+		case *[]byte:
+			*d = cloneBytes(s)
+			return nil
+		case *sql.RawBytes:
+			*d = s // no copy, the caller asked for a reference
+			return nil
+		}
+	}
+
+	switch d := dst.(type) {
+	case *string:
+		switch src.(type) {
+		case int64, float64, bool:
+			*d = asString(src)
+			return nil
+		}
+	case *[]byte:
+		switch src.(type) {
+		case int64, float64, bool:
+			*d = []byte(asString(src))
+			return nil
+		}
+	case *sql.RawBytes:
+		switch src.(type) {
+		case int64, float64, bool:
+			*d = append((*d)[:0], asString(src)...)
+			return nil
+		}
+	case *bool:
+		bv, err := driver.Bool.ConvertValue(src)
+		if err == nil {
+			*d = bv.(bool)
+			return nil
+		}
+	case *interface{}:
+		*d = src
+		return nil
+	}
+
+	if scanner, ok := dst.(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+
+	if s, ok := src.(string); ok {
+		if tu, ok := dst.(encoding.TextUnmarshaler); ok {
+			if err := tu.UnmarshalText([]byte(s)); err != nil {
+				return ScanErr{Kind: ScanErrKindTypeMismatch, err: err}
+			}
+
+			return nil
 		}
+	}
+
+	dpv := reflect.ValueOf(dst)
+	if dpv.Kind() != reflect.Ptr || dpv.IsNil() {
+		return ScanErr{Kind: ScanErrKindTypeMismatch,
+			err: fmt.Errorf("destination not a non-nil pointer, got: %T", dst)}
+	}
 
-	case src.DoubleValue != nil:
-		expType = "*float64,sql.Scanner"
-		switch p := dst.(type) {
-		case *float64:
-			*p, expType = aws.Float64Value(src.DoubleValue), ""
-		case sql.Scanner:
-			return p.Scan(*src.DoubleValue)
+	dv := dpv.Elem()
+	if dv.Kind() == reflect.Ptr {
+		dv.Set(reflect.New(dv.Type().Elem()))
+		return c.convertAssign(dv.Interface(), src)
+	}
+
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dv.Type()) {
+		if b, ok := src.([]byte); ok {
+			dv.Set(reflect.ValueOf(cloneBytes(b)))
+			return nil
 		}
 
-	case src.LongValue != nil:
-		expType = "*int64,sql.Scanner"
-		switch p := dst.(type) {
-		case *int64:
-			*p, expType = aws.Int64Value(src.LongValue), ""
-		case sql.Scanner:
-			return p.Scan(*src.LongValue)
+		dv.Set(sv)
+		return nil
+	}
+
+	if dv.Kind() == sv.Kind() && sv.Type().ConvertibleTo(dv.Type()) {
+		dv.Set(sv.Convert(dv.Type()))
+		return nil
+	}
+
+	switch dv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s := asString(src)
+		i64, err := strconv.ParseInt(s, 10, dv.Type().Bits())
+		if err != nil {
+			return ScanErr{Kind: ScanErrKindTypeMismatch,
+				err: fmt.Errorf("converting %T (%q) to a %s: %w", src, s, dv.Kind(), numError(err))}
 		}
 
-	case src.BooleanValue != nil:
-		expType = "*bool,sql.Scanner"
-		switch p := dst.(type) {
-		case *bool:
-			*p, expType = aws.BoolValue(src.BooleanValue), ""
-		case sql.Scanner:
-			return p.Scan(*src.BooleanValue)
+		dv.SetInt(i64)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s := asString(src)
+		u64, err := strconv.ParseUint(s, 10, dv.Type().Bits())
+		if err != nil {
+			return ScanErr{Kind: ScanErrKindTypeMismatch,
+				err: fmt.Errorf("converting %T (%q) to a %s: %w", src, s, dv.Kind(), numError(err))}
 		}
 
-	case src.BlobValue != nil:
-		expType = "*[]byte,*sql.RawBytes,sql.Scanner"
-		switch dt := dst.(type) {
-		case *sql.RawBytes:
-			*dt, expType = src.BlobValue, ""
-		case *[]byte:
-			*dt, expType = cloneBytes(src.BlobValue), ""
-		case sql.Scanner:
-			return dt.Scan(src.BlobValue)
+		dv.SetUint(u64)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		s := asString(src)
+		f64, err := strconv.ParseFloat(s, dv.Type().Bits())
+		if err != nil {
+			return ScanErr{Kind: ScanErrKindTypeMismatch,
+				err: fmt.Errorf("converting %T (%q) to a %s: %w", src, s, dv.Kind(), numError(err))}
 		}
 
-	default:
-		return ScanErr{ScanErrKindUnsupported,
-			fmt.Errorf("unsupported field: %s", src.String()), 0, 0}
+		dv.SetFloat(f64)
+		return nil
+	case reflect.String:
+		if b, ok := src.([]byte); ok {
+			dv.SetString(string(b))
+			return nil
+		}
+	}
+
+	return ScanErr{Kind: ScanErrKindTypeMismatch,
+		err: fmt.Errorf("unsupported scan, storing %T into type %T", src, dst)}
+}
+
+// assignNull handles a NULL field according to the codec's null policy
+func (c *codec) assignNull(dst interface{}) error {
+	if scanner, ok := dst.(sql.Scanner); ok {
+		return scanner.Scan(nil)
 	}
 
-	if expType != "" {
-		return ScanErr{ScanErrKindTypeMismatch,
-			fmt.Errorf("invalid type, expected: %s, got: %T", expType, dst), 0, 0}
+	if c.nulls == NullAsError {
+		return ScanErr{Kind: ScanErrKindNull, err: fmt.Errorf("converting NULL to %T is unsupported", dst)}
+	}
+
+	switch d := dst.(type) {
+	case *interface{}:
+		*d = nil
+		return nil
+	case *[]byte:
+		*d = nil
+		return nil
+	case *sql.RawBytes:
+		*d = nil
+		return nil
+	}
+
+	dpv := reflect.ValueOf(dst)
+	if dpv.Kind() != reflect.Ptr || dpv.IsNil() {
+		return ScanErr{Kind: ScanErrKindTypeMismatch,
+			err: fmt.Errorf("destination not a non-nil pointer, got: %T", dst)}
+	}
+
+	if dv := dpv.Elem(); dv.Kind() == reflect.Ptr || c.nulls == NullAsZero {
+		dv.Set(reflect.Zero(dv.Type()))
+		return nil
+	}
+
+	return ScanErr{Kind: ScanErrKindNull, err: fmt.Errorf("converting NULL to %T is unsupported", dst)}
+}
+
+// asString formats a field value as a string, numbers are formatted like strconv does
+func asString(src interface{}) string {
+	switch v := src.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	return fmt.Sprintf("%v", src)
+}
+
+// numError strips the strconv function and input from a parse error, those are already part of
+// the message that wraps it.
+func numError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		return ne.Err
 	}
 
-	return nil
+	return err
 }
 
 func cloneBytes(b []byte) []byte {
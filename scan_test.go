@@ -1,11 +1,13 @@
 package dasql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/rdsdataservice"
@@ -15,7 +17,7 @@ func TestScanFieldBlobRefCopy(t *testing.T) {
 	t.Run("copy", func(t *testing.T) {
 		f := &rdsdataservice.Field{BlobValue: []byte{0x01}}
 		var dst []byte
-		if err := scanField(f, &dst); err != nil {
+		if err := defaultCodec.scanField(f, &dst); err != nil {
 			t.Fatal(err)
 		}
 
@@ -29,7 +31,7 @@ func TestScanFieldBlobRefCopy(t *testing.T) {
 	t.Run("ref", func(t *testing.T) {
 		f := &rdsdataservice.Field{BlobValue: []byte{0x01}}
 		var dst sql.RawBytes
-		if err := scanField(f, &dst); err != nil {
+		if err := defaultCodec.scanField(f, &dst); err != nil {
 			t.Fatal(err)
 		}
 
@@ -125,13 +127,130 @@ func TestScanField(t *testing.T) {
 			f:          &rdsdataservice.Field{StringValue: aws.String("foo")},
 			expErrKind: ScanErrKindTypeMismatch,
 		},
+		{
+			dst: &time.Time{},
+			exp: time.Date(2020, 1, 2, 3, 4, 5, 6e6, time.UTC),
+			f:   &rdsdataservice.Field{StringValue: aws.String("2020-01-02 03:04:05.006")},
+		},
+		{
+			dst: &time.Time{},
+			exp: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+			f:   &rdsdataservice.Field{StringValue: aws.String("2020-01-02")},
+		},
+		{
+			dst: &sql.NullTime{},
+			exp: sql.NullTime{Time: time.Date(0, 1, 1, 3, 4, 5, 0, time.UTC), Valid: true},
+			f:   &rdsdataservice.Field{StringValue: aws.String("03:04:05")},
+		},
+		{
+			dst: &sql.NullTime{Valid: true},
+			exp: sql.NullTime{},
+			f:   &rdsdataservice.Field{IsNull: aws.Bool(true)},
+		},
+		{
+			dst:        &time.Time{},
+			f:          &rdsdataservice.Field{StringValue: aws.String("foo")},
+			expErrKind: ScanErrKindTypeMismatch,
+		},
 		{
 			f:          &rdsdataservice.Field{},
 			expErrKind: ScanErrKindUnsupported,
 		},
+
+		{
+			dst: new(int8),
+			exp: int8(-12),
+			f:   &rdsdataservice.Field{LongValue: aws.Int64(-12)},
+		},
+		{
+			dst:        new(int8),
+			f:          &rdsdataservice.Field{LongValue: aws.Int64(300)},
+			expErrKind: ScanErrKindTypeMismatch,
+		},
+		{
+			dst: new(uint16),
+			exp: uint16(300),
+			f:   &rdsdataservice.Field{LongValue: aws.Int64(300)},
+		},
+		{
+			dst:        new(uint),
+			f:          &rdsdataservice.Field{LongValue: aws.Int64(-1)},
+			expErrKind: ScanErrKindTypeMismatch,
+		},
+		{
+			dst: new(int),
+			exp: 42,
+			f:   &rdsdataservice.Field{StringValue: aws.String("42")},
+		},
+		{
+			dst: new(float32),
+			exp: float32(1.5),
+			f:   &rdsdataservice.Field{DoubleValue: aws.Float64(1.5)},
+		},
+		{
+			dst: new(float64),
+			exp: 12.3,
+			f:   &rdsdataservice.Field{StringValue: aws.String("12.3")},
+		},
+		{
+			dst: new(string),
+			exp: "12",
+			f:   &rdsdataservice.Field{LongValue: aws.Int64(12)},
+		},
+		{
+			dst: new(string),
+			exp: "true",
+			f:   &rdsdataservice.Field{BooleanValue: aws.Bool(true)},
+		},
+		{
+			dst: new(bool),
+			exp: true,
+			f:   &rdsdataservice.Field{LongValue: aws.Int64(1)},
+		},
+		{
+			dst: new([]byte),
+			exp: []byte("foo"),
+			f:   &rdsdataservice.Field{StringValue: aws.String("foo")},
+		},
+		{
+			dst: new(interface{}),
+			exp: int64(12),
+			f:   &rdsdataservice.Field{LongValue: aws.Int64(12)},
+		},
+		{
+			dst: new(interface{}),
+			exp: []string{"foo"},
+			f: &rdsdataservice.Field{ArrayValue: &rdsdataservice.ArrayValue{
+				StringValues: aws.StringSlice([]string{"foo"})}},
+		},
+		{
+			dst: func() **int64 { v := aws.Int64(1); return &v }(),
+			exp: (*int64)(nil),
+			f:   &rdsdataservice.Field{IsNull: aws.Bool(true)},
+		},
+		{
+			dst: new(*int32),
+			exp: func() *int32 { v := int32(12); return &v }(),
+			f:   &rdsdataservice.Field{LongValue: aws.Int64(12)},
+		},
+		{
+			dst:        new(int64),
+			f:          &rdsdataservice.Field{IsNull: aws.Bool(true)},
+			expErrKind: ScanErrKindNull,
+		},
+		{
+			dst: &sql.NullInt32{},
+			exp: sql.NullInt32{Int32: 12, Valid: true},
+			f:   &rdsdataservice.Field{LongValue: aws.Int64(12)},
+		},
+		{
+			dst:        new(int64),
+			f:          &rdsdataservice.Field{StringValue: aws.String("foo")},
+			expErrKind: ScanErrKindTypeMismatch,
+		},
 	} {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
-			err := scanField(c.f, c.dst)
+			err := defaultCodec.scanField(c.f, c.dst)
 			if serr, ok := err.(ScanErr); ok && serr.Kind != c.expErrKind {
 				t.Fatalf("exp: %v got: %v", c.expErrKind, err)
 			}
@@ -146,6 +265,52 @@ func TestScanField(t *testing.T) {
 	}
 }
 
+func TestScanNullPolicy(t *testing.T) {
+	null := &rdsdataservice.Field{IsNull: aws.Bool(true)}
+	for i, c := range []struct {
+		p          NullPolicy
+		dst        interface{}
+		exp        interface{}
+		expErrKind ScanErrKind
+	}{
+		{NullAsNil, aws.String("foo"), nil, ScanErrKindNull},
+		{NullAsNil, &[]byte{0x01}, []byte(nil), 0},
+		{NullAsNil, func() **string { v := aws.String("foo"); return &v }(), (*string)(nil), 0},
+		{NullAsNil, &sql.NullString{String: "foo", Valid: true}, sql.NullString{}, 0},
+
+		{NullAsZero, aws.String("foo"), "", 0},
+		{NullAsZero, aws.Int64(12), int64(0), 0},
+		{NullAsZero, &time.Time{}, time.Time{}, 0},
+		{NullAsZero, func() **string { v := aws.String("foo"); return &v }(), (*string)(nil), 0},
+
+		{NullAsError, aws.String("foo"), nil, ScanErrKindNull},
+		{NullAsError, &[]byte{0x01}, nil, ScanErrKindNull},
+		{NullAsError, func() **string { v := aws.String("foo"); return &v }(), nil, ScanErrKindNull},
+		{NullAsError, &sql.NullString{String: "foo", Valid: true}, sql.NullString{}, 0},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := (&codec{loc: time.UTC, nulls: c.p}).scanField(null, c.dst)
+			if c.expErrKind != 0 {
+				var se ScanErr
+				if !errors.As(err, &se) || se.Kind != c.expErrKind {
+					t.Fatalf("exp: %v got: %v", c.expErrKind, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got: %v", err)
+			}
+
+			indir := reflect.Indirect(reflect.ValueOf(c.dst)).Interface()
+			if !reflect.DeepEqual(indir, c.exp) {
+				t.Fatalf("exp: %v (%T), got: %v (%T)", c.exp, c.exp, indir, indir)
+			}
+		})
+	}
+}
+
 func TestScanArrayValues(t *testing.T) {
 	for i, c := range []struct {
 		av     *rdsdataservice.ArrayValue
@@ -272,3 +437,25 @@ func TestScanArrayValues(t *testing.T) {
 		})
 	}
 }
+
+func TestDBNullPolicy(t *testing.T) {
+	da := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{
+		ColumnMetadata: []*rdsdataservice.ColumnMetadata{{Name: aws.String("name")}},
+		Records:        [][]*rdsdataservice.Field{{{IsNull: aws.Bool(true)}}},
+	}}
+
+	rows, err := New(da, "", "", WithNullPolicy(NullAsZero)).
+		Query(context.Background(), `SELECT name FROM users`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var us []struct{ Name string }
+	if err = ScanAll(rows, &us); err != nil || len(us) != 1 || us[0].Name != "" {
+		t.Fatalf("got: %v %v", us, err)
+	}
+
+	if defaultCodec.nulls != NullAsNil {
+		t.Fatalf("got: %v", defaultCodec.nulls)
+	}
+}
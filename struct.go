@@ -0,0 +1,231 @@
+package dasql
+
+import (
+	"database/sql"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// NameMapper maps the name of a struct field to the column name it is scanned from, it is used
+// for fields that don't have a 'db' tag.
+type NameMapper func(field string) string
+
+// structMapper maps column names to struct fields, the mapping of each struct type is cached
+type structMapper struct {
+	fn    NameMapper
+	cache sync.Map // reflect.Type => map[string]structField
+}
+
+// structField is a (nested) struct field that a column is mapped to
+type structField struct {
+	index []int
+	json  bool // tagged with the 'json' option, the column holds the field as a JSON value
+}
+
+// defaultMapper maps field names to lower case, just like the columns of most databases
+var defaultMapper = newStructMapper(strings.ToLower)
+
+// newStructMapper creates a struct mapper that maps untagged field names with 'fn'
+func newStructMapper(fn NameMapper) *structMapper { return &structMapper{fn: fn} }
+
+// fields returns each struct field by its column name
+func (m *structMapper) fields(t reflect.Type) map[string]structField {
+	if fields, ok := m.cache.Load(t); ok {
+		return fields.(map[string]structField)
+	}
+
+	fields, depths := map[string]structField{}, map[string]int{}
+	m.collect(t, nil, fields, depths)
+	m.cache.Store(t, fields)
+	return fields
+}
+
+// collect adds the fields of struct 't' to 'fields'. Embedded structs without a tag are flattened,
+// their fields are shadowed by a field with the same name that is embedded less deep.
+func (m *structMapper) collect(
+	t reflect.Type, index []int, fields map[string]structField, depths map[string]int,
+) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, opts := sf.Tag.Get("db"), ""
+		if tag == "-" {
+			continue
+		}
+
+		if n := strings.IndexByte(tag, ','); n >= 0 {
+			tag, opts = tag[:n], tag[n+1:]
+		}
+
+		idx := append(append([]int{}, index...), i)
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if sf.Anonymous && tag == "" && opts == "" && ft.Kind() == reflect.Struct {
+			if sf.PkgPath != "" && sf.Type.Kind() == reflect.Ptr {
+				continue // can't be allocated when scanning
+			}
+
+			m.collect(ft, idx, fields, depths)
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue // unexported
+		}
+
+		name := tag
+		if name == "" {
+			name = m.fn(sf.Name)
+		}
+
+		if d, ok := depths[name]; ok && d <= len(idx) {
+			continue
+		}
+
+		fields[name], depths[name] = structField{idx, hasOption(opts, "json")}, len(idx)
+	}
+}
+
+// dest returns pointers to the fields of struct 'dst' in the order of 'cols'
+func (m *structMapper) dest(cols []string, dst interface{}) ([]interface{}, error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, ScanErr{Kind: ScanErrKindTypeMismatch,
+			err: fmt.Errorf("invalid type, expected: pointer to struct, got: %T", dst)}
+	}
+
+	rv = rv.Elem()
+	fields := m.fields(rv.Type())
+	dest := make([]interface{}, len(cols))
+	for i, col := range cols {
+		sf, ok := fields[col]
+		if !ok {
+			return nil, ScanErr{Kind: ScanErrKindNoField,
+				err:   fmt.Errorf("no field for column '%s' in %s", col, rv.Type()),
+				Field: i, Column: col}
+		}
+
+		dest[i] = fieldByIndex(rv, sf.index).Addr().Interface()
+		if sf.json {
+			dest[i] = JSONDest(dest[i])
+		}
+	}
+
+	return dest, nil
+}
+
+// hasOption returns whether the comma separated tag options contain 'opt'
+func hasOption(opts, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldByIndex returns the nested field, embedded pointers to structs are allocated if nil
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v
+}
+
+// ScanAll scans all rows into 'dst' and closes them. The destination must be a pointer to a slice
+// of structs (or pointers to structs) which are scanned with ScanStruct. Slices of other types,
+// including structs that scan from a single column such as time.Time, big.Float, sql.Scanner and
+// encoding.TextUnmarshaler implementations and types with a registered Decoder, are scanned with
+// Scan, the rows must have a single column in that case.
+func ScanAll(rows Rows, dst interface{}) (err error) {
+	defer func() {
+		if cerr := rows.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return ScanErr{Kind: ScanErrKindTypeMismatch,
+			err: fmt.Errorf("invalid type, expected: pointer to slice, got: %T", dst)}
+	}
+
+	sv := rv.Elem()
+	et, isPtr := sv.Type().Elem(), false
+	if et.Kind() == reflect.Ptr {
+		et, isPtr = et.Elem(), true
+	}
+
+	isStruct := !isScalar(rows, et)
+
+	for row := 0; rows.Next(); row++ {
+		ev := reflect.New(et)
+		if isStruct {
+			err = ScanStruct(rows, ev.Interface())
+		} else {
+			err = rows.Scan(ev.Interface())
+		}
+
+		var se ScanErr
+		if errors.As(err, &se) {
+			if _, ok := err.(ScanErr); !ok {
+				se.err = err // keep the context of the error that wraps it, e.g. from database/sql
+			}
+
+			se.Row = row
+			return se
+		} else if err != nil {
+			return err
+		}
+
+		if !isPtr {
+			ev = ev.Elem()
+		}
+
+		sv = reflect.Append(sv, ev)
+	}
+
+	if err := RowsErr(rows); err != nil {
+		return err
+	}
+
+	rv.Elem().Set(sv)
+	return nil
+}
+
+// decoderRows is implemented by rows that know the type registry of their DB
+type decoderRows interface {
+	decodes(t reflect.Type) bool
+}
+
+// isScalar returns whether values of type 't' are scanned from a single column rather than by
+// the fields of a struct
+func isScalar(rows Rows, t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+
+	switch reflect.New(t).Interface().(type) {
+	case sql.Scanner, encoding.TextUnmarshaler:
+		return true
+	}
+
+	dr, ok := rows.(decoderRows)
+	return ok && dr.decodes(t)
+}
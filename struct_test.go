@@ -0,0 +1,226 @@
+package dasql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// Audit is exported so it can be embedded as a pointer
+type Audit struct{ Created, Updated string }
+
+type testBase struct {
+	ID int64 `db:"id"`
+	*Audit
+}
+
+type testUser struct {
+	testBase
+	Created string
+	Name    string `db:"full_name"`
+	Email   sql.NullString
+	Ignored string `db:"-"`
+	secret  string
+}
+
+// testUserOutput returns the output of querying two users
+func testUserOutput() *rdsdataservice.ExecuteStatementOutput {
+	return &rdsdataservice.ExecuteStatementOutput{
+		ColumnMetadata: []*rdsdataservice.ColumnMetadata{
+			{Name: aws.String("id")}, {Name: aws.String("full_name")},
+			{Name: aws.String("email")}, {Name: aws.String("created")},
+			{Name: aws.String("updated")},
+		},
+		Records: [][]*rdsdataservice.Field{
+			{{LongValue: aws.Int64(1)}, {StringValue: aws.String("foo")},
+				{StringValue: aws.String("foo@x.y")}, {StringValue: aws.String("2020")},
+				{StringValue: aws.String("2021")}},
+			{{LongValue: aws.Int64(2)}, {StringValue: aws.String("bar")},
+				{IsNull: aws.Bool(true)}, {StringValue: aws.String("2019")},
+				{StringValue: aws.String("2022")}},
+		},
+	}
+}
+
+func TestScanStruct(t *testing.T) {
+	for i, db := range []interface {
+		Query(ctx context.Context, q string, args ...interface{}) (Rows, error)
+	}{
+		New(&stubDA{nextESO: testUserOutput()}, "", ""),
+		Adapt(sql.OpenDB(NewConnector(New(&stubDA{nextESO: testUserOutput()}, "", "")))),
+	} {
+		rows, err := db.Query(context.Background(), `SELECT * FROM users`)
+		if err != nil {
+			t.Fatalf("%d: got: %v", i, err)
+		}
+
+		var us []testUser
+		for rows.Next() {
+			var u testUser
+			if err = ScanStruct(rows, &u); err != nil {
+				t.Fatalf("%d: got: %v", i, err)
+			}
+
+			us = append(us, u)
+		}
+
+		if err = rows.Close(); err != nil {
+			t.Fatalf("%d: got: %v", i, err)
+		}
+
+		if len(us) != 2 || us[0].ID != 1 || us[0].Name != "foo" || us[0].Email.String != "foo@x.y" {
+			t.Fatalf("%d: got: %+v", i, us)
+		}
+
+		// the user's field shadows the one embedded deeper in the pointer to the audit
+		if us[1].Email.Valid || us[1].Created != "2019" || us[1].Audit.Created != "" ||
+			us[1].Updated != "2022" {
+			t.Fatalf("%d: got: %+v", i, us[1])
+		}
+	}
+}
+
+func TestScanAll(t *testing.T) {
+	for i, db := range []interface {
+		Query(ctx context.Context, q string, args ...interface{}) (Rows, error)
+	}{
+		New(&stubDA{nextESO: testUserOutput()}, "", ""),
+		Adapt(sql.OpenDB(NewConnector(New(&stubDA{nextESO: testUserOutput()}, "", "")))),
+	} {
+		rows, err := db.Query(context.Background(), `SELECT * FROM users`)
+		if err != nil {
+			t.Fatalf("%d: got: %v", i, err)
+		}
+
+		var us []*testUser
+		if err = ScanAll(rows, &us); err != nil {
+			t.Fatalf("%d: got: %v", i, err)
+		}
+
+		if len(us) != 2 || us[1].ID != 2 || us[1].Name != "bar" {
+			t.Fatalf("%d: got: %+v", i, us)
+		}
+	}
+
+	da := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{
+		ColumnMetadata: []*rdsdataservice.ColumnMetadata{{Name: aws.String("name")}},
+		Records: [][]*rdsdataservice.Field{
+			{{StringValue: aws.String("foo")}}, {{StringValue: aws.String("bar")}}},
+	}}
+
+	rows, err := New(da, "", "").Query(context.Background(), `SELECT name FROM users`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var names []string
+	if err = ScanAll(rows, &names); err != nil || strings.Join(names, ",") != "foo,bar" {
+		t.Fatalf("got: %v %v", names, err)
+	}
+}
+
+func TestScanStructMapper(t *testing.T) {
+	da := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{
+		ColumnMetadata: []*rdsdataservice.ColumnMetadata{{Name: aws.String("FULLNAME")}},
+		Records:        [][]*rdsdataservice.Field{{{StringValue: aws.String("foo")}}},
+	}}
+
+	rows, err := New(da, "", "", WithNameMapper(strings.ToUpper)).
+		Query(context.Background(), `SELECT * FROM users`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var us []struct{ FullName string }
+	if err = ScanAll(rows, &us); err != nil || len(us) != 1 || us[0].FullName != "foo" {
+		t.Fatalf("got: %v %v", us, err)
+	}
+}
+
+func TestScanStructErrors(t *testing.T) {
+	for i, c := range []struct {
+		dst interface{}
+		exp ScanErrKind
+	}{
+		{&struct{ Name string }{}, ScanErrKindNoField},
+		{&struct{ ID []string }{}, ScanErrKindTypeMismatch},
+		{struct{ ID int64 }{}, ScanErrKindTypeMismatch},
+		{new(string), ScanErrKindTypeMismatch},
+	} {
+		rows := &daRows{
+			recs: [][]*rdsdataservice.Field{{{LongValue: aws.Int64(1)}}}, pos: -1,
+			cols: []*rdsdataservice.ColumnMetadata{{Name: aws.String("id")}},
+		}
+
+		rows.Next()
+
+		var se ScanErr
+		if err := ScanStruct(rows, c.dst); !errors.As(err, &se) || se.Kind != c.exp {
+			t.Fatalf("%d: exp: %v got: %v", i, c.exp, err)
+		}
+	}
+
+	rows := &daRows{
+		recs: [][]*rdsdataservice.Field{{{LongValue: aws.Int64(1)}}, {{StringValue: aws.String("a")}}},
+		pos:  -1,
+		cols: []*rdsdataservice.ColumnMetadata{{Name: aws.String("id")}},
+	}
+
+	var ids []struct{ ID int64 }
+	var se ScanErr
+	if err := ScanAll(rows, &ids); !errors.As(err, &se) || se.Row != 1 {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err := ScanAll(rows, ids); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestScanAllScalarStructs(t *testing.T) {
+	ctx := context.Background()
+	out := func(v string) *rdsdataservice.ExecuteStatementOutput {
+		return &rdsdataservice.ExecuteStatementOutput{
+			ColumnMetadata: []*rdsdataservice.ColumnMetadata{{Name: aws.String("v")}},
+			Records:        [][]*rdsdataservice.Field{{{StringValue: aws.String(v)}}},
+		}
+	}
+
+	rows, err := New(&stubDA{nextESO: out("2020-01-02 03:04:05")}, "", "").Query(ctx, `SELECT v`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var ts []time.Time
+	if err = ScanAll(rows, &ts); err != nil || len(ts) != 1 || ts[0].Day() != 2 {
+		t.Fatalf("got: %v %v", ts, err)
+	}
+
+	rows, err = New(&stubDA{nextESO: out("12.5")}, "", "").Query(ctx, `SELECT v`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var fs []*big.Float
+	if err = ScanAll(rows, &fs); err != nil || len(fs) != 1 || fs[0].String() != "12.5" {
+		t.Fatalf("got: %v %v", fs, err)
+	}
+
+	rows, err = New(&stubDA{nextESO: out("POINT(1 2)")}, "", "", WithTypes(testTypes())).
+		Query(ctx, `SELECT v`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var ps []testPoint
+	if err = ScanAll(rows, &ps); err != nil || len(ps) != 1 || ps[0].Y != 2 {
+		t.Fatalf("got: %v %v", ps, err)
+	}
+}
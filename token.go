@@ -0,0 +1,93 @@
+package dasql
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTxTokenInvalid is returned when a transaction token is malformed, has an invalid
+	// signature or is for another database.
+	ErrTxTokenInvalid = errors.New("dasql: invalid transaction token")
+
+	// ErrTxTokenExpired is returned when a transaction token is used after it expired
+	ErrTxTokenExpired = errors.New("dasql: transaction token has expired")
+)
+
+// txToken is the signed content of a transaction token
+type txToken struct {
+	ID       string `json:"id"`
+	Resource string `json:"res"`
+	Expires  int64  `json:"exp"` // unix time in seconds
+}
+
+// sign returns the HMAC-SHA256 signature of the payload
+func (db *DB) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, db.txKey)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// TxToken returns a token for the transaction that can be handed to another process, which can
+// continue the transaction with ResumeTxToken until 'expires'. The token holds the transaction
+// id and the resource ARN of the database and is signed with the key that is configured with
+// WithTxTokenKey. It is not encrypted. The transaction is still rolled back when its context is
+// done, call DetachTx if this process may be done with it before the other one is.
+func (db *DB) TxToken(tx Tx, expires time.Time) (string, error) {
+	switch {
+	case len(db.txKey) == 0:
+		return "", errors.New("dasql: no transaction token key configured")
+	case TxID(tx) == "":
+		return "", errors.New("dasql: transaction has no id")
+	}
+
+	payload, err := json.Marshal(txToken{TxID(tx), db.resourceARN, expires.Unix()})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(db.sign(payload)), nil
+}
+
+// ResumeTxToken verifies the token that was created by TxToken and returns the transaction, see
+// ResumeTx: it is rolled back when 'ctx' is done, unless it is detached with DetachTx. It returns
+// ErrTxTokenInvalid or ErrTxTokenExpired if the token can't be used.
+func (db *DB) ResumeTxToken(ctx context.Context, token string) (Tx, error) {
+	if len(db.txKey) == 0 {
+		return nil, errors.New("dasql: no transaction token key configured")
+	}
+
+	enc := base64.RawURLEncoding
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, ErrTxTokenInvalid
+	}
+
+	payload, err := enc.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrTxTokenInvalid
+	}
+
+	sig, err := enc.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(sig, db.sign(payload)) {
+		return nil, ErrTxTokenInvalid
+	}
+
+	var tt txToken
+	if err = json.Unmarshal(payload, &tt); err != nil || tt.Resource != db.resourceARN {
+		return nil, ErrTxTokenInvalid
+	}
+
+	if time.Now().Unix() >= tt.Expires {
+		return nil, ErrTxTokenExpired
+	}
+
+	return db.ResumeTx(ctx, tt.ID)
+}
@@ -0,0 +1,56 @@
+package dasql
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTxToken(t *testing.T) {
+	ctx, exp := context.Background(), time.Now().Add(time.Minute)
+	db := New(&stubDA{}, "arn:aws:rds:1", "", WithTxTokenKey([]byte("secret")))
+
+	tx, _ := db.ResumeTx(ctx, "1234")
+	token, err := db.TxToken(tx, exp)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	tx, err = db.ResumeTxToken(ctx, token)
+	if err != nil || TxID(tx) != "1234" {
+		t.Fatalf("got: %v %v", tx, err)
+	}
+
+	parts := strings.Split(token, ".")
+	for i, c := range []struct {
+		db     *DB
+		token  string
+		expErr error
+	}{
+		{db, "", ErrTxTokenInvalid},
+		{db, parts[0], ErrTxTokenInvalid},
+		{db, parts[0] + ".!", ErrTxTokenInvalid},
+		{db, parts[1] + "." + parts[1], ErrTxTokenInvalid},
+		{New(&stubDA{}, "arn:aws:rds:1", "", WithTxTokenKey([]byte("other"))), token, ErrTxTokenInvalid},
+		{New(&stubDA{}, "arn:aws:rds:2", "", WithTxTokenKey([]byte("secret"))), token, ErrTxTokenInvalid},
+		{db, func() string { s, _ := db.TxToken(tx, time.Now().Add(-time.Second)); return s }(), ErrTxTokenExpired},
+	} {
+		if _, err := c.db.ResumeTxToken(ctx, c.token); !errors.Is(err, c.expErr) {
+			t.Fatalf("%d: exp: %v got: %v", i, c.expErr, err)
+		}
+	}
+
+	if _, err = New(&stubDA{}, "", "").TxToken(tx, exp); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err = New(&stubDA{}, "", "").ResumeTxToken(ctx, token); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err = db.TxToken(&stdTx{}, exp); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+}
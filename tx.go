@@ -2,7 +2,11 @@ package dasql
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/rdsdataservice"
 )
@@ -16,36 +20,171 @@ type Tx interface {
 	Rollback() error
 }
 
+// IDTx is implemented by transactions that have an id, see TxID
+type IDTx interface {
+	ID() string
+}
+
+// CursorTx is implemented by transactions that can stream a query from a server-side cursor,
+// see QueryCursor
+type CursorTx interface {
+	QueryCursor(ctx context.Context, q string, fetchSize int, args ...interface{}) (Rows, error)
+}
+
+// TxID returns the id of a Data API transaction. It is empty for transactions that don't
+// implement IDTx, such as the ones of an adapted database. The id can be used to continue the
+// transaction in another process, see DB.ResumeTx.
+func TxID(tx Tx) string {
+	if it, ok := tx.(IDTx); ok {
+		return it.ID()
+	}
+
+	return ""
+}
+
+// QueryCursor executes a query in 'tx' that returns its rows in parts of 'fetchSize' rows while
+// they are iterated. The rows can't be used after the transaction has ended. Transactions that
+// don't implement CursorTx, such as the ones of an adapted database which stream their rows
+// already, simply run the query.
+func QueryCursor(
+	ctx context.Context, tx Tx, q string, fetchSize int, args ...interface{},
+) (Rows, error) {
+	if ct, ok := tx.(CursorTx); ok {
+		return ct.QueryCursor(ctx, q, fetchSize, args...)
+	}
+
+	return tx.Query(ctx, q, args...)
+}
+
+// ErrTxDone is returned by any operation on a transaction that has already been committed or
+// rolled back, also when that happened because its context was done.
+var ErrTxDone = errors.New("dasql: transaction has already been committed or rolled back")
+
+// txRollbackTimeout bounds the rollback of a transaction whose context was done
+const txRollbackTimeout = 30 * time.Second
+
 // daTx implements the Tx interface for the Data API
 type daTx struct {
-	id  string
-	db  *DB
-	ctx context.Context
+	id      string
+	db      *DB
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    int32  // set to 1 once the transaction is committed or rolled back
+	cursors uint32 // number of cursors that were declared, see QueryCursor
+
+	detached   chan struct{} // closed when the transaction is detached from its context
+	detachOnce sync.Once
 }
 
+// newTx returns the transaction with id 'id'. If 'ctx' can be done the transaction is rolled back
+// when that happens before it is committed, like database/sql does.
+func newTx(ctx context.Context, id string, db *DB) *daTx {
+	tx := &daTx{id: id, db: db, detached: make(chan struct{})}
+	tx.ctx, tx.cancel = context.WithCancel(ctx)
+	if ctx.Done() != nil {
+		go tx.awaitDone()
+	}
+
+	return tx
+}
+
+// awaitDone rolls back the transaction when its context is done, unless it is detached first
+func (tx *daTx) awaitDone() {
+	select {
+	case <-tx.ctx.Done():
+		if !tx.isDetached() { // both may be ready by the time this runs
+			_ = tx.Rollback() // returns ErrTxDone if it was committed or rolled back already
+		}
+	case <-tx.detached:
+	}
+}
+
+// DetachTx stops the transaction from being rolled back when its context is done, so another
+// process can continue it after this one is done with it, see TxToken and DB.ResumeTx. It can
+// still be committed or rolled back after its context is done. It returns false for transactions
+// that can't be detached, such as the ones of an adapted database.
+func DetachTx(tx Tx) bool {
+	dt, ok := tx.(*daTx)
+	if ok {
+		dt.detachOnce.Do(func() { close(dt.detached) })
+	}
+
+	return ok
+}
+
+// isDetached returns whether the transaction was detached from its context, see DetachTx
+func (tx *daTx) isDetached() bool {
+	select {
+	case <-tx.detached:
+		return true
+	default:
+		return false
+	}
+}
+
+// ID returns the id of the transaction
+func (tx *daTx) ID() string { return tx.id }
+
+// isDone returns whether the transaction has been committed or rolled back
+func (tx *daTx) isDone() bool { return atomic.LoadInt32(&tx.done) == 1 }
+
 // Query executes sql that expects to return rows inside of the transaction
-func (tx daTx) Query(ctx context.Context, q string, args ...interface{}) (Rows, error) {
+func (tx *daTx) Query(ctx context.Context, q string, args ...interface{}) (Rows, error) {
+	if tx.isDone() {
+		return nil, ErrTxDone
+	}
+
 	return tx.db.query(ctx, tx.id, q, args...)
 }
 
 // Exec executes sql inside of the transaction
-func (tx daTx) Exec(ctx context.Context, q string, args ...interface{}) (Result, error) {
+func (tx *daTx) Exec(ctx context.Context, q string, args ...interface{}) (Result, error) {
+	if tx.isDone() {
+		return nil, ErrTxDone
+	}
+
 	return tx.db.exec(ctx, tx.id, q, args...)
 }
 
 // ExecBatch executes the batch as part the transaction
-func (tx daTx) ExecBatch(ctx context.Context, b *Batch) ([]Result, error) {
+func (tx *daTx) ExecBatch(ctx context.Context, b *Batch) ([]Result, error) {
+	if tx.isDone() {
+		return nil, ErrTxDone
+	}
+
 	return tx.db.execBatch(ctx, tx.id, b)
 }
 
-// Commit the transaction
-func (tx daTx) Commit() error {
+// Commit the transaction. If the context of the transaction is done it is rolled back instead
+// and the error of the context is returned, unless the transaction was detached.
+func (tx *daTx) Commit() error {
+	ctx := tx.ctx
+	if ctx.Err() != nil && tx.isDetached() {
+		ctx = context.Background()
+	}
+
+	select {
+	case <-ctx.Done():
+		if tx.isDone() {
+			return ErrTxDone
+		}
+
+		return ctx.Err()
+	default:
+	}
+
+	if !atomic.CompareAndSwapInt32(&tx.done, 0, 1) {
+		return ErrTxDone
+	}
+
+	defer tx.cancel()
+
 	in := (&rdsdataservice.CommitTransactionInput{}).
 		SetResourceArn(tx.db.resourceARN).
 		SetSecretArn(tx.db.secretARN).
 		SetTransactionId(tx.id)
 
-	_, err := tx.db.da.CommitTransactionWithContext(tx.ctx, in)
+	_, err := tx.db.da.CommitTransactionWithContext(ctx, in)
 	if err != nil {
 		return fmt.Errorf("dasql: failed to commit transaction: %w", err)
 	}
@@ -53,14 +192,33 @@ func (tx daTx) Commit() error {
 	return nil
 }
 
-// Roolback the transaction
-func (tx daTx) Rollback() error {
+// Rollback the transaction. If the context of the transaction is done a fresh context, bounded
+// by txRollbackTimeout, is used since the transaction's context can't be used anymore.
+func (tx *daTx) Rollback() error {
+	if tx.ctx.Err() == nil {
+		return tx.rollback(tx.ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), txRollbackTimeout)
+	defer cancel()
+
+	return tx.rollback(ctx)
+}
+
+// rollback rolls back the transaction with the provided context, unless that already happened
+func (tx *daTx) rollback(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&tx.done, 0, 1) {
+		return ErrTxDone
+	}
+
+	defer tx.cancel()
+
 	in := (&rdsdataservice.RollbackTransactionInput{}).
 		SetResourceArn(tx.db.resourceARN).
 		SetSecretArn(tx.db.secretARN).
 		SetTransactionId(tx.id)
 
-	_, err := tx.db.da.RollbackTransactionWithContext(tx.ctx, in)
+	_, err := tx.db.da.RollbackTransactionWithContext(ctx, in)
 	if err != nil {
 		return fmt.Errorf("dasql: failed to rollback transaction: %w", err)
 	}
@@ -6,16 +6,18 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/rdsdataservice"
 )
 
 func TestTxQuery(t *testing.T) {
 	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{}}, context.Background()
 	db := New(da, "res", "sec")
-	tx := &daTx{"1234", db, ctx}
+	tx := newTx(ctx, "1234", db)
 
 	res, err := tx.Query(ctx, `SELECT * FROM foo`)
 	if err != nil {
@@ -34,9 +36,9 @@ func TestTxQuery(t *testing.T) {
 func TestTxExec(t *testing.T) {
 	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{}}, context.Background()
 	db := New(da, "res", "sec")
-	tx := &daTx{"1234", db, ctx}
+	tx := newTx(ctx, "1234", db)
 
-	res, err := tx.Exec(ctx, `INSERT INTO foo (bar, rab) VALUES (:bar)`)
+	res, err := tx.Exec(ctx, `INSERT INTO foo (bar, rab) VALUES (:bar)`, sql.Named("bar", 1))
 	if err != nil {
 		t.Fatalf("got: %v", err)
 	}
@@ -53,7 +55,7 @@ func TestTxExec(t *testing.T) {
 func TestTxCommit(t *testing.T) {
 	da, ctx := &stubDA{}, context.Background()
 	db := New(da, "res", "sec")
-	tx := &daTx{"1234", db, ctx}
+	tx := newTx(ctx, "1234", db)
 
 	err := tx.Commit()
 	if err != nil {
@@ -71,7 +73,7 @@ func TestTxCommit(t *testing.T) {
 
 func TestTxCommitErr(t *testing.T) {
 	da, ctx := &stubDA{nextCTOE: awserr.New("400", "foo", nil)}, context.Background()
-	tx := &daTx{"1234", New(da, "", ""), ctx}
+	tx := newTx(ctx, "1234", New(da, "", ""))
 
 	err := tx.Commit()
 	if err == nil {
@@ -91,7 +93,7 @@ func TestTxCommitErr(t *testing.T) {
 func TestTxRollback(t *testing.T) {
 	da, ctx := &stubDA{}, context.Background()
 	db := New(da, "res", "sec")
-	tx := &daTx{"1234", db, ctx}
+	tx := newTx(ctx, "1234", db)
 
 	err := tx.Rollback()
 	if err != nil {
@@ -109,7 +111,7 @@ func TestTxRollback(t *testing.T) {
 
 func TestTxRollbackErr(t *testing.T) {
 	da, ctx := &stubDA{nextRTOE: awserr.New("400", "foo", nil)}, context.Background()
-	tx := &daTx{"1234", New(da, "", ""), ctx}
+	tx := newTx(ctx, "1234", New(da, "", ""))
 
 	err := tx.Rollback()
 	if err == nil {
@@ -134,8 +136,8 @@ func TestTxBatchExec(t *testing.T) {
 
 	da, ctx := &stubDA{nextBESO: beso}, context.Background()
 	db := New(da, "res", "sec")
-	tx := &daTx{"1234", db, ctx}
-	b := NewBatch(`UPDATE * WHERE bar = :foos`).
+	tx := newTx(ctx, "1234", db)
+	b := NewBatch(`UPDATE * WHERE bar = :foo`).
 		Query(sql.Named("foo", "foo1")).
 		Exec(sql.Named("foo", "foo1"))
 
@@ -152,3 +154,173 @@ func TestTxBatchExec(t *testing.T) {
 		t.Fatalf("got: %v", res)
 	}
 }
+
+// rollbackCall is a rollback as it was received by the rollbackDA
+type rollbackCall struct {
+	id       string
+	err      error // error of the context at the time of the call
+	deadline bool
+}
+
+// rollbackDA is a stub that reports its rollbacks, they may happen concurrently
+type rollbackDA struct {
+	stubDA
+	rollbacks chan rollbackCall
+}
+
+func (da *rollbackDA) RollbackTransactionWithContext(
+	ctx aws.Context,
+	in *rdsdataservice.RollbackTransactionInput,
+	opts ...request.Option) (*rdsdataservice.RollbackTransactionOutput, error) {
+	_, deadline := ctx.Deadline()
+	da.rollbacks <- rollbackCall{aws.StringValue(in.TransactionId), ctx.Err(), deadline}
+	return &rdsdataservice.RollbackTransactionOutput{}, nil
+}
+
+func TestTxCancel(t *testing.T) {
+	da := &rollbackDA{rollbacks: make(chan rollbackCall, 2)}
+	da.nextBTO = &rdsdataservice.BeginTransactionOutput{TransactionId: aws.String("1234")}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	tx, err := New(da, "", "").Tx(ctx)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	cancel()
+
+	select {
+	case call := <-da.rollbacks:
+		if call.id != "1234" || call.err != nil || !call.deadline {
+			t.Fatalf("should roll back with a fresh context with a deadline, got: %+v", call)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("should have rolled back")
+	}
+
+	if _, err = tx.Exec(context.Background(), `DELETE FROM foo`); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("got: %v", err)
+	}
+
+	if _, err = tx.Query(context.Background(), `SELECT 1`); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = tx.Commit(); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = tx.Rollback(); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("got: %v", err)
+	}
+
+	if len(da.rollbacks) != 0 {
+		t.Fatalf("should not roll back twice")
+	}
+}
+
+func TestTxRollbackCanceled(t *testing.T) {
+	da := &rollbackDA{rollbacks: make(chan rollbackCall, 1)}
+
+	// a transaction whose context is done before it was rolled back in the background
+	tx := &daTx{id: "1234", db: New(da, "", "")}
+	tx.ctx, tx.cancel = context.WithCancel(context.Background())
+	tx.cancel()
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if call := <-da.rollbacks; call.id != "1234" || call.err != nil || !call.deadline {
+		t.Fatalf("should roll back with a fresh context with a deadline, got: %+v", call)
+	}
+}
+
+func TestTxCommitNoRollback(t *testing.T) {
+	da := &rollbackDA{rollbacks: make(chan rollbackCall, 1)}
+	da.nextBTO = &rdsdataservice.BeginTransactionOutput{TransactionId: aws.String("1234")}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tx, err := New(da, "", "").Tx(ctx)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	cancel()
+
+	select {
+	case <-da.rollbacks:
+		t.Fatalf("should not roll back a committed transaction")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err = tx.Commit(); !errors.Is(err, ErrTxDone) {
+		t.Fatalf("got: %v", err)
+	}
+}
+
+func TestDetachTx(t *testing.T) {
+	da := &rollbackDA{rollbacks: make(chan rollbackCall, 1)}
+	da.nextCTO = &rdsdataservice.CommitTransactionOutput{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	tx, err := New(da, "", "").ResumeTx(ctx, "1234")
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if !DetachTx(tx) || !DetachTx(tx) {
+		t.Fatalf("should detach, also twice")
+	}
+
+	cancel()
+
+	select {
+	case call := <-da.rollbacks:
+		t.Fatalf("should not roll back a detached transaction, got: %+v", call)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastCTI.TransactionId); act != "1234" {
+		t.Fatalf("got: %v", act)
+	}
+
+	if DetachTx(&stdTx{}) {
+		t.Fatalf("should not detach an adapted transaction")
+	}
+}
+
+func TestResumeTx(t *testing.T) {
+	da, ctx := &stubDA{nextCTO: &rdsdataservice.CommitTransactionOutput{}}, context.Background()
+	db := New(da, "", "")
+
+	if _, err := db.ResumeTx(ctx, ""); err == nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	tx, err := db.ResumeTx(ctx, "1234")
+	if err != nil || TxID(tx) != "1234" {
+		t.Fatalf("got: %v %v", tx, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := aws.StringValue(da.lastCTI.TransactionId); act != "1234" {
+		t.Fatalf("got: %v", act)
+	}
+
+	if id := TxID(&stdTx{}); id != "" {
+		t.Fatalf("got: %v", id)
+	}
+}
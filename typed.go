@@ -0,0 +1,100 @@
+package dasql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+// type hints that are supported by the Data API but not (yet) declared by the SDK
+const (
+	typeHintJSON = "JSON"
+	typeHintUUID = "UUID"
+)
+
+// TimeArg is a time argument that is sent with an explicit type hint. A time.Time argument is
+// always sent as a TIMESTAMP, use Date or Time to send it as a DATE or TIME instead.
+type TimeArg struct {
+	Time time.Time
+	Hint string // rdsdataservice.TypeHintTimestamp, TypeHintDate or TypeHintTime
+}
+
+// Value implements driver.Valuer for an adapted database, which receives the time formatted for
+// its type hint in UTC. A DB formats it in its own location, see WithLocation.
+func (a TimeArg) Value() (driver.Value, error) { return defaultCodec.formatTime(a.Time, a.Hint) }
+
+// Date returns an argument that sends the date of 't' with the DATE type hint
+func Date(t time.Time) TimeArg { return TimeArg{t, rdsdataservice.TypeHintDate} }
+
+// Time returns an argument that sends the time of day of 't' with the TIME type hint
+func Time(t time.Time) TimeArg { return TimeArg{t, rdsdataservice.TypeHintTime} }
+
+// DecimalArg is a decimal number, formatted as a string, that is sent with the DECIMAL type hint
+type DecimalArg string
+
+// Decimal returns an argument that sends 's' with the DECIMAL type hint, so it can be bound to
+// a numeric column without losing precision.
+func Decimal(s string) DecimalArg { return DecimalArg(s) }
+
+// UUIDArg is a uuid, formatted as a string, that is sent with the UUID type hint
+type UUIDArg string
+
+// UUID returns an argument that sends 's' with the UUID type hint, so Postgres accepts it for a
+// uuid column without a cast.
+func UUID(s string) UUIDArg { return UUIDArg(s) }
+
+// JSONArg is a value that is marshalled with encoding/json and sent with the JSON type hint
+type JSONArg struct{ V interface{} }
+
+// Value implements driver.Valuer for an adapted database, which receives the JSON as a string
+func (a JSONArg) Value() (driver.Value, error) {
+	data, err := json.Marshal(a.V)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// JSON returns an argument that marshals 'v' and sends it with the JSON type hint, so Postgres
+// accepts it for a json or jsonb column without a cast.
+func JSON(v interface{}) JSONArg { return JSONArg{v} }
+
+var (
+	_ driver.Valuer = TimeArg{}
+	_ driver.Valuer = JSONArg{}
+	_ sql.Scanner   = &JSONDestination{}
+)
+
+// JSONDestination is a sql.Scanner that unmarshals the JSON value of a column, see JSONDest
+type JSONDestination struct{ v interface{} }
+
+// JSONDest returns a scan destination that unmarshals the JSON value of a column into 'v', which
+// must be a pointer. A NULL column is unmarshalled as the JSON null value.
+func JSONDest(v interface{}) *JSONDestination { return &JSONDestination{v} }
+
+// Scan implements sql.Scanner
+func (d *JSONDestination) Scan(src interface{}) error {
+	var data []byte
+	switch st := src.(type) {
+	case nil:
+		data = []byte("null")
+	case string:
+		data = []byte(st)
+	case []byte:
+		data = st
+	default:
+		return ScanErr{Kind: ScanErrKindTypeMismatch,
+			err: fmt.Errorf("invalid type for json, expected: string,[]byte got: %T", src)}
+	}
+
+	if err := json.Unmarshal(data, d.v); err != nil {
+		return ScanErr{Kind: ScanErrKindUnmarshal, err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package dasql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rdsdataservice"
+)
+
+func TestJSONDest(t *testing.T) {
+	for i, c := range []struct {
+		f       *rdsdataservice.Field
+		dst     interface{}
+		exp     interface{}
+		expKind ScanErrKind
+	}{
+		{&rdsdataservice.Field{StringValue: aws.String(`{"a":1}`)}, &map[string]int{},
+			map[string]int{"a": 1}, 0},
+		{&rdsdataservice.Field{StringValue: aws.String(`[1,2]`)}, &[]int{}, []int{1, 2}, 0},
+		{&rdsdataservice.Field{BlobValue: []byte(`"foo"`)}, new(string), "foo", 0},
+		{&rdsdataservice.Field{IsNull: aws.Bool(true)}, &map[string]int{"a": 1},
+			map[string]int(nil), 0},
+		{&rdsdataservice.Field{StringValue: aws.String(`{"a":`)}, &map[string]int{}, nil,
+			ScanErrKindUnmarshal},
+		{&rdsdataservice.Field{StringValue: aws.String(`"a"`)}, new(int), nil,
+			ScanErrKindUnmarshal},
+		{&rdsdataservice.Field{LongValue: aws.Int64(1)}, new(int), nil, ScanErrKindTypeMismatch},
+	} {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := defaultCodec.scanField(c.f, JSONDest(c.dst))
+
+			var se ScanErr
+			if c.expKind != 0 {
+				if !errors.As(err, &se) || se.Kind != c.expKind {
+					t.Fatalf("exp: %v got: %v", c.expKind, err)
+				}
+
+				return
+			}
+
+			if act := reflect.ValueOf(c.dst).Elem().Interface(); err != nil ||
+				!reflect.DeepEqual(act, c.exp) {
+				t.Fatalf("exp: %v got: %v (%v)", c.exp, act, err)
+			}
+		})
+	}
+}
+
+func TestJSONColumns(t *testing.T) {
+	type doc struct {
+		ID      int64             `db:"id"`
+		Payload map[string]string `db:"payload,json"`
+	}
+
+	da, ctx := &stubDA{nextESO: &rdsdataservice.ExecuteStatementOutput{
+		ColumnMetadata: []*rdsdataservice.ColumnMetadata{
+			{Name: aws.String("id")}, {Name: aws.String("payload")}},
+		Records: [][]*rdsdataservice.Field{
+			{{LongValue: aws.Int64(1)}, {StringValue: aws.String(`{"foo":"bar"}`)}},
+			{{LongValue: aws.Int64(2)}, {StringValue: aws.String(`{"foo":`)}},
+		},
+	}}, context.Background()
+
+	db := New(da, "", "")
+	rows, err := db.Query(ctx, `SELECT id, payload FROM docs`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var docs []doc
+	var se ScanErr
+	if err = ScanAll(rows, &docs); !errors.As(err, &se) || se.Kind != ScanErrKindUnmarshal ||
+		se.Row != 1 || se.Field != 1 || !strings.Contains(err.Error(), "(payload)") {
+		t.Fatalf("got: %v", err)
+	}
+
+	rows, _ = db.Query(ctx, `SELECT id, payload FROM docs`)
+	rows.Next()
+
+	var d doc
+	if err = ScanStruct(rows, &d); err != nil || d.Payload["foo"] != "bar" {
+		t.Fatalf("got: %v %v", d, err)
+	}
+
+	if _, err = db.Exec(ctx, `UPDATE docs SET payload = :payload WHERE id = :id`,
+		NamedFrom(d)); err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	if act := da.lastESI.Parameters[0]; aws.StringValue(act.TypeHint) != "JSON" ||
+		aws.StringValue(act.Value.StringValue) != `{"foo":"bar"}` {
+		t.Fatalf("got: %v", act)
+	}
+
+	// the adapted database receives the json as a string value through the driver
+	sdb := Adapt(sql.OpenDB(NewConnector(db)))
+	srows, err := sdb.Query(ctx, `SELECT id, payload FROM docs`)
+	if err != nil {
+		t.Fatalf("got: %v", err)
+	}
+
+	var sdocs []doc
+	if err = ScanAll(srows, &sdocs); !errors.As(err, &se) || se.Kind != ScanErrKindUnmarshal ||
+		se.Row != 1 || !strings.Contains(err.Error(), `"payload"`) {
+		t.Fatalf("got: %v", err)
+	}
+}